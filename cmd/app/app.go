@@ -2,11 +2,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"insider-case/internal/api/routes"
 	"insider-case/internal/api/server"
 	"insider-case/internal/config"
+	"insider-case/internal/constants"
 	"insider-case/internal/domain/message"
+	"insider-case/internal/infrastructure/blob"
+	"insider-case/internal/infrastructure/cache"
 	"insider-case/internal/infrastructure/db"
+	"insider-case/internal/infrastructure/email"
 	"insider-case/internal/infrastructure/httpclient"
 	redisInfra "insider-case/internal/infrastructure/redis"
 	"insider-case/internal/pkg/logger"
@@ -20,6 +25,9 @@ type App struct {
 	Config    *config.Config
 	Service   *message.Service
 	Scheduler *message.Scheduler
+	Janitor   *message.Janitor
+	Worker    *redisInfra.StreamWorker // Feeds and consumes the Redis stream, nil when polling
+	Redis     *redisInfra.Supervisor
 	Server    *http.Server
 }
 
@@ -31,32 +39,110 @@ func NewApp(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 
-	// Init Redis
-	var cacheRepo message.CacheRepository
-	var redisClient *redis.Client
-	if rc, err := redisInfra.InitRedis(cfg); err == nil {
-		redisClient = rc
-		cacheRepo = redisInfra.NewCacheRepository(rc, cfg.Redis.TTL)
-		logger.Info("Redis connection verified")
-	} else {
-		logger.Warn("Failed to initialize Redis, continuing without cache", "error", err)
-	}
+	// Init cache, Redis is attached by the supervisor once connected
+	tieredCache := cache.NewTieredCache(cache.NewMemoryCache(cfg.Cache.LocalSize, cfg.Cache.LocalTTL, cfg.Redis.InFlightTTL), nil)
 
 	// Init HTTP client
-	webhookClient := httpclient.NewWebhookClient(cfg)
+	webhookClient, err := httpclient.NewProviderClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// Init services
-	messageRepo := db.NewRepository(database, cfg.Database.Type)
+	messageRepo, err := db.NewRepository(database, cfg.Database.Type)
+	if err != nil {
+		return nil, err
+	}
 	messageService := message.NewService(
 		messageRepo,
-		cacheRepo,
+		tieredCache,
 		webhookClient,
 		cfg.Scheduler.MessagesPerBatch,
-		cfg.Message.MaxLength,
-		cfg.Webhook.MaxRetryAttempts,
+		cfg.Message.MaxSegments,
+		cfg.Message.SplitLong,
+		message.NewRetryPolicy(cfg.Retry.MaxAttempts, cfg.Retry.AttemptsPerRun),
 		cfg.Scheduler.RetryBaseDelay,
 	)
-	messageScheduler := message.NewScheduler(messageService, cfg.Scheduler.Interval, cfg.Scheduler.ProcessingTimeout)
+	if err := registerChannels(messageService, cfg); err != nil {
+		return nil, err
+	}
+
+	// Init attachments
+	blobStore, err := blob.NewLocalStore(cfg.Attachment.Dir)
+	if err != nil {
+		return nil, err
+	}
+	attachmentService := message.NewAttachmentService(
+		db.NewAttachmentRepository(database),
+		messageRepo,
+		blobStore,
+		message.AttachmentPolicy{AllowedTypes: cfg.Attachment.AllowedTypes, MaxBytes: cfg.Attachment.MaxBytes},
+		cfg.Attachment.PublicBaseURL,
+	)
+	messageService.EnableAttachments(attachmentService)
+
+	streamQueue, err := setupQueue(messageService, messageRepo, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Init Redis, reconnecting in the background if it is down
+	redisSupervisor := redisInfra.NewSupervisor(cfg, func(client *redis.Client) {
+		if streamQueue != nil {
+			streamQueue.SetClient(client)
+		}
+		if client == nil {
+			tieredCache.SetRemote(nil)
+			messageService.SetListCache(nil)
+			return
+		}
+		tieredCache.SetRemote(redisInfra.NewCacheRepository(client, cfg.Redis.TTL, cfg.Redis.InFlightTTL))
+		if cfg.Cache.ListEnabled {
+			// Sends while Redis was away were not invalidated
+			listCache := redisInfra.NewListCache(client, cfg.Cache.ListPageTTL, cfg.Cache.ListCountTTL)
+			if err := listCache.Flush(context.Background()); err != nil {
+				logger.Warn("Failed to flush list cache, leaving it disabled", "error", err)
+				return
+			}
+			messageService.SetListCache(listCache)
+		}
+	})
+	if err := redisSupervisor.Connect(); err == nil {
+		logger.Info("Redis connection verified")
+	} else {
+		logger.Warn("Failed to initialize Redis, using the in-memory cache until it is reachable", "error", err)
+	}
+	redisSupervisor.Start()
+
+	// With Redis Streams the worker reads the stream and the scheduler only switches it on and off
+	var messageScheduler *message.Scheduler
+	var streamWorker *redisInfra.StreamWorker
+	if streamQueue != nil {
+		streamWorker = redisInfra.NewStreamWorker(streamQueue, messageService, cfg.Scheduler.ProcessingTimeout)
+		messageScheduler = streamWorker.Scheduler(cfg.Scheduler.Interval, cfg.Scheduler.ProcessingTimeout)
+	} else {
+		messageScheduler = message.NewScheduler(messageService, cfg.Scheduler.Interval, cfg.Scheduler.ProcessingTimeout)
+	}
+
+	// Housekeeping
+	archiver := message.NewArchiver(
+		db.NewArchiveRepository(database, cfg.Database.Type),
+		messageService,
+		cfg.Retention.Messages,
+		cfg.Retention.ArchiveBatchSize,
+		cfg.Retention.ArchiveDryRun,
+	)
+	janitor := message.NewJanitor(
+		cfg.Retention.Interval,
+		cfg.Retention.Timeout,
+		message.NewAttemptPruner(messageRepo, cfg.Retention.Attempts, cfg.Retention.BatchSize),
+		message.NewAttachmentPruner(attachmentService, cfg.Retention.Attachments, cfg.Retention.BatchSize),
+		archiver,
+	)
+	janitor.Start()
+	if streamWorker != nil {
+		streamWorker.Start()
+	}
 
 	// Start scheduler if auto-start enabled
 	if cfg.Scheduler.AutoStart {
@@ -66,17 +152,59 @@ func NewApp(cfg *config.Config) (*App, error) {
 	}
 
 	// Setup routes and start server
-	router := routes.SetupRoutes(messageService, messageScheduler, cfg, database, redisClient)
+	router := routes.SetupRoutes(messageService, messageScheduler, attachmentService, archiver, tieredCache, cfg, database, redisSupervisor)
 	srv := server.Start(router, &cfg.Server)
 
 	return &App{
 		Config:    cfg,
 		Service:   messageService,
 		Scheduler: messageScheduler,
+		Janitor:   janitor,
+		Worker:    streamWorker,
+		Redis:     redisSupervisor,
 		Server:    srv,
 	}, nil
 }
 
+// registerChannels adds the email and push channels that are configured
+func registerChannels(service *message.Service, cfg *config.Config) error {
+	if cfg.Email.Host != "" {
+		sender, err := email.NewSMTPSender(&cfg.Email)
+		if err != nil {
+			return err
+		}
+		service.RegisterChannel(constants.ChannelEmail, sender, &message.EmailValidator{MaxBodyBytes: cfg.Email.MaxBodyBytes})
+		logger.Info("Email channel enabled", "smtp_host", cfg.Email.Host)
+	}
+
+	if cfg.Push.Webhook.URL != "" {
+		sender, err := httpclient.NewPushClient(&cfg.Push.Webhook)
+		if err != nil {
+			return err
+		}
+		service.RegisterChannel(constants.ChannelPush, sender, &message.PushValidator{MaxPayloadBytes: cfg.Push.MaxPayloadBytes})
+		logger.Info("Push channel enabled", "url", cfg.Push.Webhook.URL)
+	}
+
+	return nil
+}
+
+// setupQueue selects the queue backend. With Redis Streams it returns the
+// queue, which gets its client from the Redis supervisor.
+func setupQueue(service *message.Service, repo message.Repository, cfg *config.Config) (*redisInfra.StreamQueue, error) {
+	switch cfg.Queue.Backend {
+	case constants.QueueBackendPostgres:
+		return nil, nil
+	case constants.QueueBackendRedisStreams:
+		queue := redisInfra.NewStreamQueue(repo, &cfg.Queue)
+		service.UseQueue(queue)
+		logger.Info("Using Redis Streams queue", "stream", cfg.Queue.Stream, "group", cfg.Queue.Group, "consumer", cfg.Queue.Consumer)
+		return queue, nil
+	default:
+		return nil, fmt.Errorf("unsupported queue backend: %s", cfg.Queue.Backend)
+	}
+}
+
 func (a *App) Shutdown() {
 	logger.Info("Shutting down...")
 
@@ -89,10 +217,17 @@ func (a *App) Shutdown() {
 		}
 	}
 
+	a.Janitor.Stop()
+	if a.Worker != nil {
+		a.Worker.Stop()
+	}
+
 	// Shutdown server
 	if err := server.Shutdown(a.Server, a.Config.Server.ShutdownTimeout); err != nil {
 		logger.Error("Server shutdown error", "error", err)
 	}
 
+	a.Redis.Stop()
+
 	time.Sleep(1 * time.Second)
 }
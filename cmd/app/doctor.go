@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	redisInfra "insider-case/internal/infrastructure/redis"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// Check results
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// checkResult is one line of the doctor report
+type checkResult struct {
+	Name   string
+	State  string
+	Detail string
+}
+
+// doctor checks that the dependencies of the service are reachable. It exits
+// 1 when a required dependency fails, warnings do not change the exit code.
+func doctor(args []string) int {
+	cfg, flags, ok := loadConfig("doctor", args, nil)
+	if !ok {
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "doctor takes no arguments")
+		return exitUsage
+	}
+
+	results := []checkResult{
+		checkDatabase(cfg),
+		checkRedis(cfg),
+		checkWebhook(cfg),
+	}
+
+	code := exitOK
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, result := range results {
+		fmt.Fprintf(out, "%s\t%s\t%s\n", result.Name, result.State, result.Detail)
+		if result.State == checkFail {
+			code = exitFailure
+		}
+	}
+	_ = out.Flush()
+	return code
+}
+
+// checkDatabase connects and reports pending or modified migrations
+func checkDatabase(cfg *config.Config) checkResult {
+	result := checkResult{Name: "database"}
+
+	migrator, err := newMigrator(cfg)
+	if err != nil {
+		result.State, result.Detail = checkFail, err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		result.State, result.Detail = checkFail, err.Error()
+		return result
+	}
+
+	pending, drift := 0, 0
+	for _, status := range statuses {
+		switch {
+		case status.Missing, status.Modified:
+			drift++
+		case !status.Applied:
+			pending++
+		}
+	}
+
+	switch {
+	case drift > 0:
+		result.State, result.Detail = checkFail, fmt.Sprintf("%d migrations modified or missing", drift)
+	case pending > 0:
+		result.State, result.Detail = checkWarn, fmt.Sprintf("%d migrations pending", pending)
+	default:
+		result.State, result.Detail = checkOK, fmt.Sprintf("%s, %d migrations applied", cfg.Database.Type, len(statuses))
+	}
+	return result
+}
+
+// checkRedis pings Redis, which is only required by the Redis Streams queue
+func checkRedis(cfg *config.Config) checkResult {
+	result := checkResult{Name: "redis", State: checkOK, Detail: fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port)}
+
+	client, err := redisInfra.InitRedis(cfg)
+	if err != nil {
+		result.State, result.Detail = checkWarn, err.Error()
+		if cfg.Queue.Backend == constants.QueueBackendRedisStreams {
+			result.State = checkFail
+		}
+		return result
+	}
+	_ = client.Close()
+	return result
+}
+
+// checkWebhook makes a HEAD request to the provider. Any HTTP response counts
+// as reachable, credentials and payloads are not verified.
+func checkWebhook(cfg *config.Config) checkResult {
+	result := checkResult{Name: "webhook", Detail: cfg.Webhook.URL}
+	if cfg.Webhook.URL == "" {
+		result.State, result.Detail = checkFail, "WEBHOOK_URL is not set"
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Webhook.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, cfg.Webhook.URL, nil)
+	if err != nil {
+		result.State, result.Detail = checkFail, err.Error()
+		return result
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		result.State, result.Detail = checkFail, err.Error()
+		return result
+	}
+	_ = resp.Body.Close()
+
+	result.State = checkOK
+	result.Detail = fmt.Sprintf("%s (HTTP %d)", cfg.Webhook.URL, resp.StatusCode)
+	return result
+}
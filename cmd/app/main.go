@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,13 +11,86 @@ import (
 	"insider-case/internal/pkg/logger"
 )
 
+// Exit codes, stable for deployment pipelines
+const (
+	exitOK      = 0
+	exitFailure = 1 // The command ran and failed: a migration error, a failed check
+	exitUsage   = 2 // Unknown command or invalid arguments
+	exitPending = 3 // migrate status: migrations are pending
+)
+
+const usage = `Usage: app [command] [flags]
+
+Commands:
+  serve                 Start the API server and scheduler (default)
+  migrate up            Apply pending migrations
+  migrate down [N]      Revert the last N migrations (default 1)
+  migrate status        List migrations, exits 3 when some are pending
+  migrate redo          Revert and reapply the last applied migration
+  migrate create NAME   Write empty migration files for every dialect
+  seed                  Load fixtures into the database
+  doctor                Check database, Redis and webhook reachability
+
+Run "app <command> -h" for the flags of a command.
+`
+
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	command := "serve"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		return serve(args)
+	case "migrate":
+		return migrate(args)
+	case "seed":
+		return seed(args)
+	case "doctor":
+		return doctor(args)
+	case "help":
+		fmt.Print(usage)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		return exitUsage
+	}
+}
+
+// loadConfig parses the flags shared by every command and loads the configuration
+func loadConfig(name string, args []string, define func(flags *flag.FlagSet)) (*config.Config, *flag.FlagSet, bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	migrationsDir := flags.String("migrations-dir", "", "Read migrations from this directory instead of the embedded ones (overrides MIGRATIONS_DIR)")
+	if define != nil {
+		define(flags)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, false
+	}
+
 	cfg := config.Load()
+	if *migrationsDir != "" {
+		cfg.Database.MigrationsDir = *migrationsDir
+	}
 	logger.Init(cfg.Env)
+	return cfg, flags, true
+}
+
+func serve(args []string) int {
+	cfg, _, ok := loadConfig("serve", args, nil)
+	if !ok {
+		return exitUsage
+	}
 
 	app, err := NewApp(cfg)
 	if err != nil {
-		logger.Fatal("Failed to initialize application", "error", err)
+		logger.Error("Failed to initialize application", "error", err)
+		return exitFailure
 	}
 
 	quit := make(chan os.Signal, 1)
@@ -23,4 +98,5 @@ func main() {
 	<-quit
 
 	app.Shutdown()
+	return exitOK
 }
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/infrastructure/db"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// migrate runs the migrate subcommands
+func migrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+	subcommand, args := args[0], args[1:]
+
+	if subcommand == "create" {
+		return migrateCreate(args)
+	}
+
+	cfg, flags, ok := loadConfig("migrate "+subcommand, args, nil)
+	if !ok {
+		return exitUsage
+	}
+
+	steps := 1
+	switch subcommand {
+	case "up", "status", "redo":
+		if flags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "migrate %s takes no arguments\n", subcommand)
+			return exitUsage
+		}
+	case "down":
+		if flags.NArg() > 1 {
+			fmt.Fprintln(os.Stderr, "usage: migrate down [N]")
+			return exitUsage
+		}
+		if flags.NArg() == 1 {
+			n, err := strconv.Atoi(flags.Arg(0))
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "invalid number of migrations %q\n", flags.Arg(0))
+				return exitUsage
+			}
+			steps = n
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", subcommand, usage)
+		return exitUsage
+	}
+
+	migrator, err := newMigrator(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	ctx := context.Background()
+
+	switch subcommand {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		fmt.Printf("reverted %d migrations\n", reverted)
+	case "redo":
+		migration, err := migrator.Redo(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		if migration == nil {
+			fmt.Println("no applied migrations to redo")
+			break
+		}
+		fmt.Printf("redid migration %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		return migrateStatus(ctx, migrator)
+	}
+	return exitOK
+}
+
+// migrateStatus prints every migration. It exits 1 on drift (modified or
+// missing files) and 3 when migrations are pending.
+func migrateStatus(ctx context.Context, migrator *db.Migrator) int {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	pending, drift := 0, 0
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		switch {
+		case status.Missing:
+			state = "missing"
+			drift++
+		case status.Modified:
+			state = "modified"
+			drift++
+		case status.Applied:
+			state = "applied"
+		default:
+			pending++
+		}
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(out, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	_ = out.Flush()
+
+	switch {
+	case drift > 0:
+		return exitFailure
+	case pending > 0:
+		return exitPending
+	default:
+		return exitOK
+	}
+}
+
+// migrateCreate writes empty migration files into the source tree
+func migrateCreate(args []string) int {
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", "migrations", "Migrations source directory with one subdirectory per dialect")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: migrate create [-dir migrations] NAME")
+		return exitUsage
+	}
+
+	created, err := db.CreateMigration(*dir, flags.Arg(0))
+	for _, path := range created {
+		fmt.Println("created", path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// newMigrator connects to the configured database without migrating it
+func newMigrator(cfg *config.Config) (*db.Migrator, error) {
+	dbType := cfg.Database.Type
+	if dbType == "" {
+		dbType = constants.DBTypePostgres
+	}
+
+	files, err := db.MigrationFiles(dbType, cfg.Database.MigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	database, err := db.ConnectDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		return nil, err
+	}
+	return db.NewMigrator(sqlDB, dbType, files)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"insider-case/internal/constants"
+	"insider-case/internal/infrastructure/db"
+	"os"
+)
+
+// seed loads the pending fixtures into an already migrated database. It
+// refuses to run in production.
+func seed(args []string) int {
+	var dir *string
+	cfg, flags, ok := loadConfig("seed", args, func(flags *flag.FlagSet) {
+		dir = flags.String("dir", "", "Read fixtures from this directory instead of the embedded ones (overrides FIXTURES_DIR)")
+	})
+	if !ok {
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "seed takes no arguments")
+		return exitUsage
+	}
+	if cfg.Env == constants.EnvProduction {
+		fmt.Fprintln(os.Stderr, db.ErrSeedInProduction)
+		return exitFailure
+	}
+	if *dir != "" {
+		cfg.Database.FixturesDir = *dir
+	}
+
+	database, err := db.ConnectDB(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	result, err := db.SeedFixtures(context.Background(), database, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	fmt.Printf("loaded %d fixtures (%d messages), %d already loaded\n", result.Applied, result.Messages, result.Skipped)
+	return exitOK
+}
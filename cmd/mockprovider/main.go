@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"insider-case/internal/config"
+	"insider-case/internal/mockprovider"
+	"insider-case/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	cfg := config.LoadMockProvider()
+	logger.Init(cfg.Env)
+	if cfg.Env != "local" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	provider, err := mockprovider.NewServer(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize mock provider", "error", err)
+	}
+
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:           provider.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.Info("Mock provider listening", "addr", srv.Addr, "faults", cfg.Faults, "latency", cfg.Latency.Distribution)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Mock provider failed", "error", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Mock provider shutdown error", "error", err)
+	}
+	provider.Close()
+}
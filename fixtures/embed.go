@@ -0,0 +1,12 @@
+// Package fixtures embeds the versioned sample data loaded by the seed command
+// and SEED_ON_START
+package fixtures
+
+import "embed"
+
+// FS holds the whole directory, so .yaml, .yml and .json fixtures are all
+// included; the loader skips files that are not named like a fixture, such as
+// this one.
+//
+//go:embed *
+var FS embed.FS
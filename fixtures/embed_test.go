@@ -0,0 +1,24 @@
+package fixtures
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFS_EmbedsEveryFixture(t *testing.T) {
+	entries, err := os.ReadDir(".")
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) == ".go" {
+			continue
+		}
+		_, err := fs.Stat(FS, entry.Name())
+		assert.NoError(t, err, "%s is not embedded", entry.Name())
+	}
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"errors"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveController handles message archive requests
+type ArchiveController struct {
+	archiver *message.Archiver
+}
+
+// NewArchiveController creates a new ArchiveController
+func NewArchiveController(archiver *message.Archiver) *ArchiveController {
+	return &ArchiveController{archiver: archiver}
+}
+
+// GetStats retrieves archiver counters
+// @Summary      Get archive statistics
+// @Description  Retrieves how many messages were archived, deleted and restored since startup
+// @Tags         archive
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}  "Archive statistics"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Router       /api/v1/archive/stats [get]
+func (c *ArchiveController) GetStats(ctx *gin.Context) {
+	response.OK(ctx, response.SuccessCodeArchiveStatsRetrieved, "Archive statistics retrieved successfully", c.archiver.Stats())
+}
+
+// Restore moves an archived message back into the messages table
+// @Summary      Restore archived message
+// @Description  Moves an archived message with its parts and delivery attempts back, keeping its ID
+// @Tags         archive
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "Message ID"
+// @Success      200  {object}  map[string]interface{}  "Restored message"
+// @Failure      400  {object}  map[string]interface{}  "Invalid message ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Archived message not found"
+// @Failure      409  {object}  map[string]interface{}  "Message already exists"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/archive/messages/{id}/restore [post]
+func (c *ArchiveController) Restore(ctx *gin.Context) {
+	id, ok := messageIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	msg, err := c.archiver.Restore(ctx.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, message.ErrArchivedNotFound):
+			response.NotFound(ctx, response.ErrorCodeArchivedMessageNotFound, "Archived message not found")
+		case errors.Is(err, message.ErrMessageExists):
+			response.Conflict(ctx, response.ErrorCodeMessageExists, "Message already exists")
+		default:
+			response.InternalServerError(ctx, response.ErrorCodeFailedToRestoreMessage, "Failed to restore message", err)
+		}
+		return
+	}
+
+	response.OK(ctx, response.SuccessCodeMessageRestored, "Message restored successfully", msg)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"errors"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/response"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is allowed on top of the attachment size for multipart framing
+const multipartOverhead = 1 << 20
+
+// AttachmentController handles media attachment requests
+type AttachmentController struct {
+	service  *message.AttachmentService
+	maxBytes int64
+}
+
+// AttachURLRequest attaches media hosted elsewhere
+type AttachURLRequest struct {
+	URL         string `json:"url" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+// NewAttachmentController creates a new AttachmentController
+func NewAttachmentController(service *message.AttachmentService, maxBytes int64) *AttachmentController {
+	return &AttachmentController{
+		service:  service,
+		maxBytes: maxBytes,
+	}
+}
+
+// Create attaches media to a queued message, by multipart upload or by URL
+// @Summary      Attach media to a message
+// @Description  Uploads a file (multipart field "file") or attaches a URL (JSON body) to a queued message
+// @Tags         attachments
+// @Accept       json,mpfd
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id    path      int               true   "Message ID"
+// @Param        file  formData  file              false  "Media file"
+// @Param        body  body      AttachURLRequest  false  "Media URL"
+// @Success      201   {object}  map[string]interface{}  "Created attachment"
+// @Failure      400   {object}  map[string]interface{}  "Invalid attachment"
+// @Failure      404   {object}  map[string]interface{}  "Message not found"
+// @Failure      409   {object}  map[string]interface{}  "Message is no longer queued"
+// @Router       /api/v1/messages/{id}/attachments [post]
+func (c *AttachmentController) Create(ctx *gin.Context) {
+	id, ok := messageIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var (
+		attachment *message.Attachment
+		err        error
+	)
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxBytes+multipartOverhead)
+		fileHeader, formErr := ctx.FormFile("file")
+		if formErr != nil {
+			response.BadRequest(ctx, response.ErrorCodeInvalidAttachment, "Multipart field \"file\" is required")
+			return
+		}
+		file, openErr := fileHeader.Open()
+		if openErr != nil {
+			response.BadRequest(ctx, response.ErrorCodeInvalidAttachment, "Failed to read uploaded file")
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		attachment, err = c.service.Upload(ctx.Request.Context(), id, fileHeader.Filename, file)
+	} else {
+		var req AttachURLRequest
+		if bindErr := ctx.ShouldBindJSON(&req); bindErr != nil {
+			response.BadRequest(ctx, response.ErrorCodeInvalidAttachment, "url and content_type are required")
+			return
+		}
+		attachment, err = c.service.AttachURL(ctx.Request.Context(), id, req.URL, req.ContentType, req.SizeBytes)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, message.ErrMessageNotFound):
+			response.NotFound(ctx, response.ErrorCodeMessageNotFound, "Message not found")
+		case errors.Is(err, message.ErrInvalidMessageStatus):
+			response.Conflict(ctx, response.ErrorCodeMessageNotQueued, "Attachments can only be added to queued messages")
+		case errors.Is(err, message.ErrInvalidAttachment):
+			response.BadRequest(ctx, response.ErrorCodeInvalidAttachment, err.Error())
+		default:
+			response.InternalServerError(ctx, response.ErrorCodeFailedToStoreAttachment, "Failed to store attachment", err)
+		}
+		return
+	}
+
+	response.Created(ctx, response.SuccessCodeAttachmentCreated, "Attachment created successfully", attachment)
+}
+
+// List retrieves the attachments of a message
+// @Summary      List message attachments
+// @Tags         attachments
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "Message ID"
+// @Success      200  {object}  map[string]interface{}  "Attachments"
+// @Router       /api/v1/messages/{id}/attachments [get]
+func (c *AttachmentController) List(ctx *gin.Context) {
+	id, ok := messageIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	attachments, err := c.service.List(ctx.Request.Context(), id)
+	if err != nil {
+		response.InternalServerError(ctx, response.ErrorCodeFailedToRetrieveMessage, "Failed to retrieve attachments", err)
+		return
+	}
+
+	response.OK(ctx, response.SuccessCodeAttachmentsRetrieved, "Attachments retrieved successfully", attachments)
+}
+
+// Serve streams uploaded media to providers
+func (c *AttachmentController) Serve(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	attachment, content, err := c.service.Open(ctx.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, message.ErrAttachmentNotFound) {
+			response.NotFound(ctx, response.ErrorCodeAttachmentNotFound, "Attachment not found")
+			return
+		}
+		response.InternalServerError(ctx, response.ErrorCodeFailedToRetrieveMedia, "Failed to retrieve media", err)
+		return
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	ctx.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.ContentType, content, nil)
+}
+
+// messageIDParam parses the :id path parameter, answering 400 when it is invalid
+func messageIDParam(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.BadRequest(ctx, response.ErrorCodeInvalidMessageID, "Message ID must be a positive integer")
+		return 0, false
+	}
+	return uint(id), true
+}
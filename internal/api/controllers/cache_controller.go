@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"insider-case/internal/domain/message"
+	"insider-case/internal/infrastructure/cache"
+	"insider-case/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheController handles cache administration requests
+type CacheController struct {
+	cache   *cache.TieredCache
+	service *message.Service
+}
+
+// NewCacheController creates a new CacheController
+func NewCacheController(cache *cache.TieredCache, service *message.Service) *CacheController {
+	return &CacheController{
+		cache:   cache,
+		service: service,
+	}
+}
+
+// GetStats retrieves cache statistics
+// @Summary      Get cache statistics
+// @Description  Retrieves hit, miss and eviction counters of the local cache and Redis
+// @Tags         cache
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}  "Cache statistics"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Router       /api/v1/cache/stats [get]
+func (c *CacheController) GetStats(ctx *gin.Context) {
+	response.OK(ctx, response.SuccessCodeCacheStatsRetrieved, "Cache statistics retrieved successfully", c.cache.Stats())
+}
+
+// Flush drops every cached sent message page and count
+// @Summary      Flush list cache
+// @Description  Drops the cached sent message pages and counts, the next reads go to the database
+// @Tags         cache
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}  "Cache flushed"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/cache/flush [post]
+func (c *CacheController) Flush(ctx *gin.Context) {
+	if err := c.service.FlushListCache(ctx.Request.Context()); err != nil {
+		response.InternalServerError(ctx, response.ErrorCodeFailedToFlushCache, "Failed to flush cache", err)
+		return
+	}
+
+	response.OK(ctx, response.SuccessCodeCacheFlushed, "Cache flushed successfully", nil)
+}
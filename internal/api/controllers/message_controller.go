@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"insider-case/internal/config"
+	"insider-case/internal/constants"
 	"insider-case/internal/domain/message"
 	"insider-case/internal/pkg/response"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,6 +36,8 @@ func NewMessageController(service *message.Service, msgConfig *config.MessageCon
 // @Param        limit   query     int     false  "Limit (default: 10)"  default(10)
 // @Param        offset  query     int     false  "Offset (default: 0)"  default(0)
 // @Success      200     {object}  map[string]interface{}  "Sent messages with pagination info"
+// @Header       200     {string}  X-Cache  "HIT when served from the list cache, MISS otherwise"
+// @Header       200     {integer} Age      "Seconds since the cached page was stored, set on HIT"
 // @Failure      401     {object}  map[string]interface{}  "Unauthorized"
 // @Failure      500     {object}  map[string]interface{}  "Internal server error"
 // @Router       /api/v1/messages/sent [get]
@@ -50,16 +55,117 @@ func (c *MessageController) GetSentMessages(ctx *gin.Context) {
 		offset = c.config.DefaultOffset
 	}
 
-	messages, total, err := c.service.GetSentMessages(ctx.Request.Context(), limit, offset)
+	page, err := c.service.GetSentMessages(ctx.Request.Context(), limit, offset)
 	if err != nil {
 		response.InternalServerError(ctx, response.ErrorCodeFailedToRetrieveMessages, "Failed to retrieve sent messages", err)
 		return
 	}
 
+	if page.Cached {
+		ctx.Header(constants.HeaderCache, constants.CacheHit)
+		ctx.Header(constants.HeaderAge, strconv.Itoa(int(time.Since(page.CachedAt).Seconds())))
+	} else {
+		ctx.Header(constants.HeaderCache, constants.CacheMiss)
+	}
+
 	response.OK(ctx, response.SuccessCodeMessagesRetrieved, "Sent messages retrieved successfully", gin.H{
-		"messages": messages,
-		"total":    total,
+		"messages": page.Messages,
+		"total":    page.Total,
 		"limit":    limit,
 		"offset":   offset,
 	})
 }
+
+// GetMessage retrieves a message together with every delivery attempt made for it
+// @Summary      Get message detail
+// @Description  Retrieves a message and its delivery attempts, including provider responses
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "Message ID"
+// @Success      200  {object}  map[string]interface{}  "Message with its delivery attempts"
+// @Failure      400  {object}  map[string]interface{}  "Invalid message ID"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404  {object}  map[string]interface{}  "Message not found"
+// @Failure      500  {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/messages/{id} [get]
+func (c *MessageController) GetMessage(ctx *gin.Context) {
+	id, ok := messageIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	detail, err := c.service.GetMessageDetail(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, message.ErrMessageNotFound) {
+			response.NotFound(ctx, response.ErrorCodeMessageNotFound, "Message not found")
+			return
+		}
+		response.InternalServerError(ctx, response.ErrorCodeFailedToRetrieveMessage, "Failed to retrieve message", err)
+		return
+	}
+
+	response.OK(ctx, response.SuccessCodeMessageRetrieved, "Message retrieved successfully", detail)
+}
+
+// GetMessageByProviderID retrieves a message by the messageId returned by the provider
+// @Summary      Get message by provider messageId
+// @Description  Looks up a message by the provider messageId of the message or one of its parts, using the Redis cache when possible
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        messageId  path      string  true  "Provider messageId"
+// @Success      200        {object}  map[string]interface{}  "Message, whether it was cached and the cached sent_at"
+// @Failure      401        {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404        {object}  map[string]interface{}  "Message not found"
+// @Failure      500        {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/messages/by-provider-id/{messageId} [get]
+func (c *MessageController) GetMessageByProviderID(ctx *gin.Context) {
+	result, err := c.service.GetMessageByProviderID(ctx.Request.Context(), ctx.Param("messageId"))
+	if err != nil {
+		if errors.Is(err, message.ErrMessageNotFound) {
+			response.NotFound(ctx, response.ErrorCodeMessageNotFound, "Message not found")
+			return
+		}
+		response.InternalServerError(ctx, response.ErrorCodeFailedToRetrieveMessage, "Failed to retrieve message", err)
+		return
+	}
+
+	response.OK(ctx, response.SuccessCodeMessageRetrieved, "Message retrieved successfully", result)
+}
+
+// HandleDeliveryReceipt records a delivery receipt posted by the provider
+// @Summary      Record delivery receipt
+// @Description  Accepts the delivery receipt a provider posts for a messageId it returned, marking the message or part delivered
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        receipt  body      message.DeliveryReceiptRequest  true  "Delivery receipt"
+// @Success      200      {object}  map[string]interface{}  "Message the receipt belongs to"
+// @Failure      400      {object}  map[string]interface{}  "Invalid receipt"
+// @Failure      401      {object}  map[string]interface{}  "Unauthorized"
+// @Failure      404      {object}  map[string]interface{}  "Message not found"
+// @Failure      500      {object}  map[string]interface{}  "Internal server error"
+// @Router       /api/v1/messages/receipts [post]
+func (c *MessageController) HandleDeliveryReceipt(ctx *gin.Context) {
+	var req message.DeliveryReceiptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, response.ErrorCodeInvalidReceipt, "messageId and status are required")
+		return
+	}
+
+	msg, err := c.service.HandleDeliveryReceipt(ctx.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, message.ErrMessageNotFound) {
+			response.NotFound(ctx, response.ErrorCodeMessageNotFound, "Message not found")
+			return
+		}
+		response.InternalServerError(ctx, response.ErrorCodeFailedToRecordReceipt, "Failed to record delivery receipt", err)
+		return
+	}
+
+	response.OK(ctx, response.SuccessCodeReceiptRecorded, "Delivery receipt recorded successfully", msg)
+}
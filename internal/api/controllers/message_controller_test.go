@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"insider-case/internal/config"
 	"insider-case/internal/domain/message"
+	"insider-case/internal/infrastructure/cache"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,9 +19,14 @@ import (
 type MockRepository struct {
 	GetSentMessagesFunc   func(ctx context.Context, limit, offset int) ([]*message.Message, error)
 	CountSentMessagesFunc func(ctx context.Context) (int64, error)
+	GetMessageByIDFunc    func(ctx context.Context, id uint) (*message.Message, error)
+	GetAttemptsFunc       func(ctx context.Context, messageID uint) ([]*message.MessageAttempt, error)
+
+	GetMessageByProviderIDFunc func(ctx context.Context, providerID string) (*message.Message, error)
+	MarkMessageDeliveredFunc   func(ctx context.Context, id uint, deliveredAt time.Time) error
 }
 
-func (m *MockRepository) GetUnsentMessages(ctx context.Context, limit int, maxRetryAttempts int) ([]*message.Message, error) {
+func (m *MockRepository) GetUnsentMessages(ctx context.Context, limit int) ([]*message.Message, error) {
 	return nil, nil
 }
 
@@ -39,6 +46,10 @@ func (m *MockRepository) UpdateMessageRetry(ctx context.Context, id uint, retryC
 	return nil
 }
 
+func (m *MockRepository) UpdateMessageEncoding(ctx context.Context, id uint, encoding string, segmentCount int) error {
+	return nil
+}
+
 func (m *MockRepository) GetSentMessages(ctx context.Context, limit, offset int) ([]*message.Message, error) {
 	if m.GetSentMessagesFunc != nil {
 		return m.GetSentMessagesFunc(ctx, limit, offset)
@@ -53,6 +64,62 @@ func (m *MockRepository) CountSentMessages(ctx context.Context) (int64, error) {
 	return 0, nil
 }
 
+func (m *MockRepository) GetMessageByID(ctx context.Context, id uint) (*message.Message, error) {
+	if m.GetMessageByIDFunc != nil {
+		return m.GetMessageByIDFunc(ctx, id)
+	}
+	return nil, message.ErrMessageNotFound
+}
+
+func (m *MockRepository) GetMessagesByIDs(ctx context.Context, ids []uint) ([]*message.Message, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) GetMessageByProviderID(ctx context.Context, providerID string) (*message.Message, error) {
+	if m.GetMessageByProviderIDFunc != nil {
+		return m.GetMessageByProviderIDFunc(ctx, providerID)
+	}
+	return nil, message.ErrMessageNotFound
+}
+
+func (m *MockRepository) MarkMessageDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	if m.MarkMessageDeliveredFunc != nil {
+		return m.MarkMessageDeliveredFunc(ctx, id, deliveredAt)
+	}
+	return nil
+}
+
+func (m *MockRepository) GetMessageParts(ctx context.Context, messageID uint) ([]*message.MessagePart, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) CreateMessageParts(ctx context.Context, parts []*message.MessagePart) error {
+	return nil
+}
+
+func (m *MockRepository) UpdateMessagePart(ctx context.Context, part *message.MessagePart) error {
+	return nil
+}
+
+func (m *MockRepository) MarkPartDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	return nil
+}
+
+func (m *MockRepository) RecordAttempt(ctx context.Context, attempt *message.MessageAttempt) error {
+	return nil
+}
+
+func (m *MockRepository) GetAttempts(ctx context.Context, messageID uint) ([]*message.MessageAttempt, error) {
+	if m.GetAttemptsFunc != nil {
+		return m.GetAttemptsFunc(ctx, messageID)
+	}
+	return []*message.MessageAttempt{}, nil
+}
+
+func (m *MockRepository) DeleteAttemptsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
 // MockWebhookClient for testing
 type MockWebhookClient struct{}
 
@@ -83,9 +150,9 @@ func TestMessageController_GetSentMessages(t *testing.T) {
 		},
 	}
 	mockWebhook := &MockWebhookClient{}
-	service := message.NewService(mockRepo, nil, mockWebhook, 2, 1000, 3, 3*time.Second)
+	service := message.NewService(mockRepo, nil, mockWebhook, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
 	msgConfig := &config.MessageConfig{
-		MaxLength:     1000,
+		MaxSegments:   6,
 		DefaultLimit:  10,
 		DefaultOffset: 0,
 	}
@@ -124,9 +191,9 @@ func TestMessageController_GetSentMessages_WithPagination(t *testing.T) {
 			return 0, nil
 		},
 	}
-	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 1000, 3, 3*time.Second)
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
 	msgConfig := &config.MessageConfig{
-		MaxLength:     1000,
+		MaxSegments:   6,
 		DefaultLimit:  10,
 		DefaultOffset: 0,
 	}
@@ -156,9 +223,9 @@ func TestMessageController_GetSentMessages_DefaultPagination(t *testing.T) {
 			return 0, nil
 		},
 	}
-	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 1000, 3, 3*time.Second)
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
 	msgConfig := &config.MessageConfig{
-		MaxLength:     1000,
+		MaxSegments:   6,
 		DefaultLimit:  10,
 		DefaultOffset: 0,
 	}
@@ -189,9 +256,9 @@ func TestMessageController_GetSentMessages_InvalidLimit(t *testing.T) {
 			return 0, nil
 		},
 	}
-	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 1000, 3, 3*time.Second)
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
 	msgConfig := &config.MessageConfig{
-		MaxLength:     1000,
+		MaxSegments:   6,
 		DefaultLimit:  10,
 		DefaultOffset: 0,
 	}
@@ -218,9 +285,9 @@ func TestMessageController_GetSentMessages_ServiceError(t *testing.T) {
 			return 0, nil
 		},
 	}
-	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 1000, 3, 3*time.Second)
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
 	msgConfig := &config.MessageConfig{
-		MaxLength:     1000,
+		MaxSegments:   6,
 		DefaultLimit:  10,
 		DefaultOffset: 0,
 	}
@@ -237,3 +304,307 @@ func TestMessageController_GetSentMessages_ServiceError(t *testing.T) {
 		t.Errorf("expected status 500, got %d", w.Code)
 	}
 }
+
+func TestMessageController_GetMessage(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetMessageByIDFunc: func(ctx context.Context, id uint) (*message.Message, error) {
+			return &message.Message{ID: id, To: "+905551111111", Status: message.MessageStatusSent}, nil
+		},
+		GetAttemptsFunc: func(ctx context.Context, messageID uint) ([]*message.MessageAttempt, error) {
+			return []*message.MessageAttempt{
+				{ID: 1, MessageID: messageID, AttemptNumber: 1, StatusCode: 500, Error: "unexpected status code: 500"},
+				{ID: 2, MessageID: messageID, AttemptNumber: 2, StatusCode: 202, ResponseBody: `{"message":"Accepted"}`},
+			}, nil
+		},
+	}
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+	controller := NewMessageController(service, &config.MessageConfig{DefaultLimit: 10})
+
+	router := gin.New()
+	router.GET("/messages/:id", controller.GetMessage)
+
+	req := httptest.NewRequest("GET", "/messages/7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	data := response["data"].(map[string]interface{})
+	attempts := data["attempts"].([]interface{})
+	if len(attempts) != 2 {
+		t.Errorf("expected 2 attempts, got %d", len(attempts))
+	}
+}
+
+func TestMessageController_GetMessage_NotFound(t *testing.T) {
+	service := message.NewService(&MockRepository{}, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+	controller := NewMessageController(service, &config.MessageConfig{DefaultLimit: 10})
+
+	router := gin.New()
+	router.GET("/messages/:id", controller.GetMessage)
+
+	req := httptest.NewRequest("GET", "/messages/42", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestMessageController_GetMessage_InvalidID(t *testing.T) {
+	service := message.NewService(&MockRepository{}, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+	controller := NewMessageController(service, &config.MessageConfig{DefaultLimit: 10})
+
+	router := gin.New()
+	router.GET("/messages/:id", controller.GetMessage)
+
+	req := httptest.NewRequest("GET", "/messages/abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func lookupByProviderID(t *testing.T, service *message.Service, providerID string) (int, map[string]interface{}) {
+	controller := NewMessageController(service, &config.MessageConfig{DefaultLimit: 10})
+
+	router := gin.New()
+	router.GET("/messages/by-provider-id/:messageId", controller.GetMessageByProviderID)
+
+	req := httptest.NewRequest("GET", "/messages/by-provider-id/"+providerID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	data, _ := response["data"].(map[string]interface{})
+	return w.Code, data
+}
+
+func TestMessageController_GetMessageByProviderID_FromCache(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetMessageByIDFunc: func(ctx context.Context, id uint) (*message.Message, error) {
+			return &message.Message{ID: id, MessageID: "abc", Status: message.MessageStatusSent}, nil
+		},
+		GetMessageByProviderIDFunc: func(ctx context.Context, providerID string) (*message.Message, error) {
+			t.Error("database lookup should not be used on a cache hit")
+			return nil, message.ErrMessageNotFound
+		},
+	}
+	memory := cache.NewMemoryCache(10, time.Hour, time.Minute)
+	if err := memory.SetMessageID(context.Background(), 7, "abc", time.Unix(1700000000, 0)); err != nil {
+		t.Fatalf("failed to cache messageId: %v", err)
+	}
+	service := message.NewService(mockRepo, memory, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+
+	code, data := lookupByProviderID(t, service, "abc")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if data["cached"] != true {
+		t.Errorf("expected cached response, got %v", data["cached"])
+	}
+	if data["sent_at"] == nil {
+		t.Error("expected cached sent_at")
+	}
+}
+
+func TestMessageController_GetMessageByProviderID_FallsBackToDatabase(t *testing.T) {
+	mockRepo := &MockRepository{
+		GetMessageByProviderIDFunc: func(ctx context.Context, providerID string) (*message.Message, error) {
+			return &message.Message{ID: 7, MessageID: providerID, Status: message.MessageStatusSent}, nil
+		},
+	}
+	service := message.NewService(mockRepo, cache.NewMemoryCache(10, time.Hour, time.Minute), &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+
+	code, data := lookupByProviderID(t, service, "abc")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if data["cached"] != false {
+		t.Errorf("expected uncached response, got %v", data["cached"])
+	}
+	if _, ok := data["sent_at"]; ok {
+		t.Error("expected no sent_at without a cache hit")
+	}
+}
+
+func TestMessageController_GetMessageByProviderID_NotFound(t *testing.T) {
+	service := message.NewService(&MockRepository{}, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+
+	code, _ := lookupByProviderID(t, service, "missing")
+	if code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", code)
+	}
+}
+
+func postReceipt(t *testing.T, service *message.Service, body string) int {
+	controller := NewMessageController(service, &config.MessageConfig{DefaultLimit: 10})
+
+	router := gin.New()
+	router.POST("/messages/receipts", controller.HandleDeliveryReceipt)
+
+	req := httptest.NewRequest("POST", "/messages/receipts", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestMessageController_HandleDeliveryReceipt(t *testing.T) {
+	var markedID uint
+	var markedAt time.Time
+	mockRepo := &MockRepository{
+		GetMessageByProviderIDFunc: func(ctx context.Context, providerID string) (*message.Message, error) {
+			return &message.Message{ID: 7, MessageID: providerID, Status: message.MessageStatusSent}, nil
+		},
+		MarkMessageDeliveredFunc: func(ctx context.Context, id uint, deliveredAt time.Time) error {
+			markedID = id
+			markedAt = deliveredAt
+			return nil
+		},
+	}
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+
+	code := postReceipt(t, service, `{"messageId":"abc","to":"+905551111111","status":"delivered","deliveredAt":"2024-01-02T03:04:05Z"}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if markedID != 7 {
+		t.Errorf("expected message 7 to be marked delivered, got %d", markedID)
+	}
+	if !markedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("expected the receipt's delivery time, got %v", markedAt)
+	}
+}
+
+func TestMessageController_HandleDeliveryReceipt_NotFound(t *testing.T) {
+	service := message.NewService(&MockRepository{}, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+
+	code := postReceipt(t, service, `{"messageId":"missing","status":"delivered"}`)
+	if code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", code)
+	}
+}
+
+func TestMessageController_HandleDeliveryReceipt_InvalidBody(t *testing.T) {
+	service := message.NewService(&MockRepository{}, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+
+	code := postReceipt(t, service, `{"status":"delivered"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", code)
+	}
+}
+
+// memoryListCache keeps sent message pages and the count in memory
+type memoryListCache struct {
+	pages map[[2]int]*message.CachedPage
+	count *message.CachedCount
+}
+
+func newMemoryListCache() *memoryListCache {
+	return &memoryListCache{pages: make(map[[2]int]*message.CachedPage)}
+}
+
+func (c *memoryListCache) GetSentPage(ctx context.Context, limit, offset int) (*message.CachedPage, error) {
+	return c.pages[[2]int{limit, offset}], nil
+}
+
+func (c *memoryListCache) SetSentPage(ctx context.Context, limit, offset int, messages []*message.Message) error {
+	c.pages[[2]int{limit, offset}] = &message.CachedPage{Messages: messages, CachedAt: time.Now().Add(-3 * time.Second)}
+	return nil
+}
+
+func (c *memoryListCache) GetSentCount(ctx context.Context) (*message.CachedCount, error) {
+	return c.count, nil
+}
+
+func (c *memoryListCache) SetSentCount(ctx context.Context, total int64) error {
+	c.count = &message.CachedCount{Total: total, CachedAt: time.Now().Add(-3 * time.Second)}
+	return nil
+}
+
+func (c *memoryListCache) InvalidateSent(ctx context.Context, createdAt time.Time) error {
+	return c.Flush(ctx)
+}
+
+func (c *memoryListCache) Flush(ctx context.Context) error {
+	c.pages = make(map[[2]int]*message.CachedPage)
+	c.count = nil
+	return nil
+}
+
+func TestMessageController_GetSentMessages_ListCache(t *testing.T) {
+	queries := 0
+	mockRepo := &MockRepository{
+		GetSentMessagesFunc: func(ctx context.Context, limit, offset int) ([]*message.Message, error) {
+			queries++
+			return []*message.Message{{ID: 1, Status: message.MessageStatusSent}}, nil
+		},
+		CountSentMessagesFunc: func(ctx context.Context) (int64, error) {
+			return 1, nil
+		},
+	}
+	service := message.NewService(mockRepo, nil, &MockWebhookClient{}, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
+	service.SetListCache(newMemoryListCache())
+	controller := NewMessageController(service, &config.MessageConfig{MaxSegments: 6, DefaultLimit: 10})
+	cacheController := NewCacheController(cache.NewTieredCache(cache.NewMemoryCache(10, time.Minute, time.Minute), nil), service)
+
+	router := gin.New()
+	router.GET("/messages/sent", controller.GetSentMessages)
+	router.POST("/cache/flush", cacheController.Flush)
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/messages/sent", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		return w
+	}
+
+	w := get()
+	if got := w.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("expected X-Cache MISS, got %q", got)
+	}
+	if got := w.Header().Get("Age"); got != "" {
+		t.Errorf("expected no Age header on a miss, got %q", got)
+	}
+
+	w = get()
+	if got := w.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("expected X-Cache HIT, got %q", got)
+	}
+	if got := w.Header().Get("Age"); got != "3" {
+		t.Errorf("expected Age 3, got %q", got)
+	}
+	if queries != 1 {
+		t.Errorf("expected 1 database query, got %d", queries)
+	}
+
+	flush := httptest.NewRecorder()
+	router.ServeHTTP(flush, httptest.NewRequest("POST", "/cache/flush", nil))
+	if flush.Code != http.StatusOK {
+		t.Fatalf("expected flush status 200, got %d", flush.Code)
+	}
+
+	w = get()
+	if got := w.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("expected X-Cache MISS after flush, got %q", got)
+	}
+	if queries != 2 {
+		t.Errorf("expected 2 database queries, got %d", queries)
+	}
+}
@@ -15,7 +15,7 @@ import (
 
 type mockRepo struct{}
 
-func (m *mockRepo) GetUnsentMessages(ctx context.Context, limit int, maxRetryAttempts int) ([]*message.Message, error) {
+func (m *mockRepo) GetUnsentMessages(ctx context.Context, limit int) ([]*message.Message, error) {
 	return nil, nil
 }
 
@@ -35,6 +35,10 @@ func (m *mockRepo) UpdateMessageRetry(ctx context.Context, id uint, retryCount i
 	return nil
 }
 
+func (m *mockRepo) UpdateMessageEncoding(ctx context.Context, id uint, encoding string, segmentCount int) error {
+	return nil
+}
+
 func (m *mockRepo) GetSentMessages(ctx context.Context, limit, offset int) ([]*message.Message, error) {
 	return nil, nil
 }
@@ -43,6 +47,50 @@ func (m *mockRepo) CountSentMessages(ctx context.Context) (int64, error) {
 	return 0, nil
 }
 
+func (m *mockRepo) GetMessageByID(ctx context.Context, id uint) (*message.Message, error) {
+	return nil, message.ErrMessageNotFound
+}
+
+func (m *mockRepo) GetMessagesByIDs(ctx context.Context, ids []uint) ([]*message.Message, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) GetMessageByProviderID(ctx context.Context, providerID string) (*message.Message, error) {
+	return nil, message.ErrMessageNotFound
+}
+
+func (m *mockRepo) MarkMessageDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	return nil
+}
+
+func (m *mockRepo) GetMessageParts(ctx context.Context, messageID uint) ([]*message.MessagePart, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) CreateMessageParts(ctx context.Context, parts []*message.MessagePart) error {
+	return nil
+}
+
+func (m *mockRepo) UpdateMessagePart(ctx context.Context, part *message.MessagePart) error {
+	return nil
+}
+
+func (m *mockRepo) MarkPartDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	return nil
+}
+
+func (m *mockRepo) RecordAttempt(ctx context.Context, attempt *message.MessageAttempt) error {
+	return nil
+}
+
+func (m *mockRepo) GetAttempts(ctx context.Context, messageID uint) ([]*message.MessageAttempt, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) DeleteAttemptsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
 type mockWebhook struct{}
 
 func (m *mockWebhook) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
@@ -56,7 +104,7 @@ func setupSenderController() (*SenderController, *gin.Engine) {
 	// Create real scheduler with mock service
 	mockRepo := &mockRepo{}
 	mockWebhook := &mockWebhook{}
-	service := message.NewService(mockRepo, nil, mockWebhook, 2, 1000, 3, 3*time.Second)
+	service := message.NewService(mockRepo, nil, mockWebhook, 2, 6, false, message.NewRetryPolicy(4, 2), 3*time.Second)
 	scheduler := message.NewScheduler(service, 1*time.Minute, 30*time.Second)
 	controller := NewSenderController(scheduler)
 
@@ -14,6 +14,9 @@ func setupAPIRoutes(
 	router *gin.Engine,
 	senderController *controllers.SenderController,
 	messageController *controllers.MessageController,
+	attachmentController *controllers.AttachmentController,
+	cacheController *controllers.CacheController,
+	archiveController *controllers.ArchiveController,
 	cfg *config.Config,
 ) {
 	v1 := router.Group(constants.APIV1BasePath)
@@ -32,6 +35,25 @@ func setupAPIRoutes(
 		messages := v1.Group(constants.MessagesBasePath)
 		{
 			messages.GET(constants.SentMessagesPath, messageController.GetSentMessages)
+			messages.GET(constants.MessageByIDPath, messageController.GetMessage)
+			messages.GET(constants.MessageByProviderIDPath, messageController.GetMessageByProviderID)
+			messages.POST(constants.DeliveryReceiptsPath, messageController.HandleDeliveryReceipt)
+			messages.POST(constants.AttachmentsPath, attachmentController.Create)
+			messages.GET(constants.AttachmentsPath, attachmentController.List)
+		}
+
+		// Cache endpoints
+		cacheGroup := v1.Group(constants.CacheBasePath)
+		{
+			cacheGroup.GET(constants.CacheStatsPath, cacheController.GetStats)
+			cacheGroup.POST(constants.CacheFlushPath, cacheController.Flush)
+		}
+
+		// Archive endpoints
+		archive := v1.Group(constants.ArchiveBasePath)
+		{
+			archive.GET(constants.ArchiveStatsPath, archiveController.GetStats)
+			archive.POST(constants.ArchiveRestorePath, archiveController.Restore)
 		}
 	}
 }
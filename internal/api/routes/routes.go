@@ -4,9 +4,10 @@ import (
 	"insider-case/internal/api/controllers"
 	"insider-case/internal/config"
 	"insider-case/internal/domain/message"
+	"insider-case/internal/infrastructure/cache"
+	redisInfra "insider-case/internal/infrastructure/redis"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 )
 
@@ -14,21 +15,27 @@ import (
 func SetupRoutes(
 	messageService *message.Service,
 	scheduler *message.Scheduler,
+	attachmentService *message.AttachmentService,
+	archiver *message.Archiver,
+	tieredCache *cache.TieredCache,
 	cfg *config.Config,
 	database *gorm.DB,
-	redisClient *redis.Client,
+	redisSupervisor *redisInfra.Supervisor,
 ) *gin.Engine {
 	router := gin.Default()
 
 	// Initialize controllers
 	senderController := controllers.NewSenderController(scheduler)
 	messageController := controllers.NewMessageController(messageService, &cfg.Message)
+	attachmentController := controllers.NewAttachmentController(attachmentService, cfg.Attachment.MaxBytes)
+	cacheController := controllers.NewCacheController(tieredCache, messageService)
+	archiveController := controllers.NewArchiveController(archiver)
 
 	// System routes (no base path)
-	setupSystemRoutes(router, database, redisClient)
+	setupSystemRoutes(router, attachmentController, database, redisSupervisor)
 
 	// API v1 routes
-	setupAPIRoutes(router, senderController, messageController, cfg)
+	setupAPIRoutes(router, senderController, messageController, attachmentController, cacheController, archiveController, cfg)
 
 	return router
 }
@@ -3,27 +3,31 @@ package routes
 import (
 	"context"
 	_ "insider-case/docs" // Swagger documentation
+	"insider-case/internal/api/controllers"
 	"insider-case/internal/constants"
+	redisInfra "insider-case/internal/infrastructure/redis"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/gorm"
 )
 
 // setupSystemRoutes configures system-level routes (health, swagger)
-func setupSystemRoutes(router *gin.Engine, database *gorm.DB, redisClient *redis.Client) {
+func setupSystemRoutes(router *gin.Engine, attachmentController *controllers.AttachmentController, database *gorm.DB, redisSupervisor *redisInfra.Supervisor) {
 	// Health check endpoint (DB + Redis)
-	router.GET(constants.HealthPath, healthCheck(database, redisClient))
+	router.GET(constants.HealthPath, healthCheck(database, redisSupervisor))
 
 	// Swagger UI documentation
 	router.GET(constants.SwaggerPath, ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Uploaded media, fetched by providers
+	router.GET(constants.MediaPath, attachmentController.Serve)
 }
 
 // healthCheck handles health check with DB and Redis connection status
-func healthCheck(database *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
+func healthCheck(database *gorm.DB, redisSupervisor *redisInfra.Supervisor) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		status := gin.H{}
 
@@ -31,7 +35,7 @@ func healthCheck(database *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
 		status["db"] = checkDatabaseHealth(database)
 
 		// Check Redis connection
-		status["redis"] = checkRedisHealth(redisClient)
+		status["redis"] = redisSupervisor.Status()
 
 		c.JSON(200, status)
 	}
@@ -57,19 +61,3 @@ func checkDatabaseHealth(database *gorm.DB) string {
 
 	return "ok"
 }
-
-// checkRedisHealth checks Redis connection health
-func checkRedisHealth(redisClient *redis.Client) string {
-	if redisClient == nil {
-		return "not_configured"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		return "error"
-	}
-
-	return "ok"
-}
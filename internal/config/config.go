@@ -15,9 +15,16 @@ type Config struct {
 	Server      ServerConfig
 	Database    DatabaseConfig
 	Redis       RedisConfig
+	Cache       CacheConfig
 	Webhook     WebhookConfig
+	Email       EmailConfig
+	Push        PushConfig
+	Retry       RetryConfig
 	Scheduler   SchedulerConfig
 	Message     MessageConfig
+	Attachment  AttachmentConfig
+	Retention   RetentionConfig
+	Queue       QueueConfig
 	AccessToken string
 }
 
@@ -39,11 +46,16 @@ type DatabaseConfig struct {
 	User            string
 	Password        string
 	Name            string
+	Path            string        // Database file, SQLite only
 	MaxOpenConns    int           // Connection pool: max open connections
 	MaxIdleConns    int           // Connection pool: max idle connections
 	ConnMaxLifetime time.Duration // Connection pool: max connection lifetime
 	ConnMaxIdleTime time.Duration // Connection pool: max idle time
 	LogLevel        string        // GORM log level: silent, error, warn, info
+	AutoMigrate     bool          // Run GORM AutoMigrate after the SQL migrations, on by default only when ENV=local
+	MigrationsDir   string        // External migrations directory, the embedded migrations are used when empty
+	SeedOnStart     bool          // Load pending fixtures after migrating, ignored in production
+	FixturesDir     string        // External fixtures directory, the embedded fixtures are used when empty
 }
 
 // RedisConfig holds Redis configuration
@@ -53,23 +65,136 @@ type RedisConfig struct {
 	Password       string
 	DB             int
 	TTL            time.Duration
+	InFlightTTL    time.Duration // How long an unconfirmed provider call blocks resending
 	ConnectTimeout time.Duration
+
+	ReconnectMinBackoff time.Duration // First delay between reconnection attempts
+	ReconnectMaxBackoff time.Duration // Upper bound of the doubling delay
+	HealthInterval      time.Duration // How often a connected client is pinged
 }
 
 // WebhookConfig holds webhook configuration
 type WebhookConfig struct {
-	URL              string
-	AuthKey          string // X-Ins-Auth-Key header value
-	Timeout          time.Duration
-	MaxRetryAttempts int // Maximum retry attempts for webhook calls
-	RetryDelay       time.Duration
+	Provider   string // Provider adapter: generic, twilio, vonage or batch
+	URL        string
+	AuthKey    string // X-Ins-Auth-Key header value
+	Timeout    time.Duration
+	RetryDelay time.Duration // Delay between attempts made within one scheduler run
+	RateLimit  int           // Max requests per second to the provider, 0 disables pacing
+
+	// Provider adapter credentials
+	SenderID  string // Sender number or alphanumeric ID (From)
+	APIKey    string // API key for providers that expect credentials in the body
+	APISecret string // API secret for providers that expect credentials in the body
+
+	// Provider payload mapping (generic provider only)
+	Method                string // HTTP method used for sending
+	SuccessStatusCodes    []int  // Status codes treated as accepted
+	ContentType           string // Content-Type of the rendered request body
+	RequestTemplate       string // Go text/template for the request body, empty sends {"to","content"}
+	ResponseMessageIDPath string // Dot separated JSON path of the provider message ID
+	ResponseStatusPath    string // Dot separated JSON path of the provider status
+
+	// HMAC request signing, disabled when SigningSecret is empty
+	SigningSecret         string // Current signing secret
+	SigningSecretPrevious string // Previous secret, still signed with during key rotation
+	SignatureHeader       string // Header carrying the signature(s)
+	TimestampHeader       string // Header carrying the signed timestamp
+
+	Auth WebhookAuthConfig
+}
+
+// WebhookAuthConfig holds outbound authentication configuration for the provider
+type WebhookAuthConfig struct {
+	Type       string // header, basic, bearer, oauth2 or none
+	HeaderName string // Header name for the header type, value comes from AuthKey
+	Username   string // Basic auth username
+	Password   string // Basic auth password
+	Token      string // Static bearer token
+
+	// OAuth2 client-credentials grant
+	TokenURL      string
+	ClientID      string
+	ClientSecret  string
+	Scopes        []string
+	RefreshBefore time.Duration // Refresh the token this long before it expires
+}
+
+// EmailConfig holds SMTP configuration of the email channel, disabled when Host is empty
+type EmailConfig struct {
+	Host         string
+	Port         string
+	Username     string // SMTP AUTH PLAIN username, empty disables authentication
+	Password     string
+	From         string // Envelope and header sender address
+	StartTLS     bool   // Require STARTTLS before authenticating
+	Timeout      time.Duration
+	MaxBodyBytes int
+}
+
+// PushConfig holds configuration of the push notification channel, disabled when Webhook.URL is empty
+type PushConfig struct {
+	Webhook         WebhookConfig
+	MaxPayloadBytes int
+}
+
+// RetryConfig holds the delivery retry policy shared by the transport and the service
+type RetryConfig struct {
+	MaxAttempts    int // Total provider calls allowed per message
+	AttemptsPerRun int // Provider calls allowed per message within one scheduler run
+}
+
+// AttachmentConfig holds media attachment configuration
+type AttachmentConfig struct {
+	Dir           string   // Local blob store directory for uploads
+	PublicBaseURL string   // Base URL providers fetch uploaded media from
+	MaxBytes      int64    // Maximum attachment size
+	AllowedTypes  []string // Allowed MIME types
+}
+
+// CacheConfig holds the in-process cache configuration
+type CacheConfig struct {
+	LocalSize int           // Maximum entries kept in memory
+	LocalTTL  time.Duration // How long entries are kept in memory
+
+	// Read-through Redis cache of sent message pages and counts
+	ListEnabled  bool
+	ListPageTTL  time.Duration
+	ListCountTTL time.Duration
+}
+
+// QueueConfig holds the queue backend configuration
+type QueueConfig struct {
+	Backend      string        // postgres (polling) or redis-streams
+	Stream       string        // Stream key
+	Group        string        // Consumer group
+	Consumer     string        // Consumer name, unique per instance
+	Block        time.Duration // How long a claim waits for new entries
+	ClaimIdle    time.Duration // Pending entries idle this long are taken over from their consumer
+	MaxLen       int64         // Approximate stream length cap
+	FeedInterval time.Duration // How often queued rows are moved into the stream
+	FeedBatch    int           // Rows moved per feed
+}
+
+// RetentionConfig holds housekeeping configuration
+type RetentionConfig struct {
+	Interval    time.Duration // How often housekeeping runs, 0 disables it
+	Timeout     time.Duration // Timeout for a single housekeeping task
+	Attempts    time.Duration // How long delivery attempts are kept, 0 keeps them forever
+	Attachments time.Duration // How long attachments of processed messages are kept, 0 keeps them forever
+	BatchSize   int           // Rows deleted per statement
+
+	Messages         time.Duration // How long sent, delivered, failed and cancelled messages are kept before archiving, 0 keeps them forever
+	ArchiveBatchSize int           // Messages archived per transaction
+	ArchiveDryRun    bool          // Only count the messages that would be archived
 }
 
 // MessageConfig holds message-related configuration
 type MessageConfig struct {
-	MaxLength     int // Maximum character limit for message content
-	DefaultLimit  int // Default pagination limit
-	DefaultOffset int // Default pagination offset
+	MaxSegments   int  // Maximum SMS segments a message may be billed as
+	SplitLong     bool // Send multi-segment messages as separately delivered concatenated parts
+	DefaultLimit  int  // Default pagination limit
+	DefaultOffset int  // Default pagination offset
 }
 
 // SchedulerConfig holds scheduler configuration
@@ -104,8 +229,9 @@ func Load() *Config {
 	if os.Getenv("ENV") == "local" {
 		_ = LoadEnvFile()
 	}
+	env := getEnv("ENV", constants.EnvProduction)
 	return &Config{
-		Env: getEnv("ENV", "production"),
+		Env: env,
 		Server: ServerConfig{
 			Port:            getEnv("SERVER_PORT", "8080"),
 			Host:            getEnv("SERVER_HOST", "0.0.0.0"),
@@ -121,11 +247,16 @@ func Load() *Config {
 			User:            getEnv("DB_USER", constants.DefaultDBUser),
 			Password:        getEnv("DB_PASSWORD", constants.DefaultDBPassword),
 			Name:            getEnv("DB_NAME", constants.DefaultDBName),
+			Path:            getEnv("DB_PATH", "./data/messages.db"),
 			MaxOpenConns:    25,
 			MaxIdleConns:    5,
 			ConnMaxLifetime: 5 * time.Minute,
 			ConnMaxIdleTime: 10 * time.Minute,
 			LogLevel:        getEnv("DB_LOG_LEVEL", "info"), // silent, error, warn, info
+			AutoMigrate:     getEnvAsBool("DB_AUTO_MIGRATE", env == constants.EnvLocal),
+			MigrationsDir:   getEnv("MIGRATIONS_DIR", ""),
+			SeedOnStart:     getEnvAsBool("SEED_ON_START", false),
+			FixturesDir:     getEnv("FIXTURES_DIR", ""),
 		},
 		Redis: RedisConfig{
 			Host:           getEnv("REDIS_HOST", "localhost"),
@@ -133,14 +264,80 @@ func Load() *Config {
 			Password:       getEnv("REDIS_PASSWORD", ""),
 			DB:             getEnvAsInt("REDIS_DB", 0),
 			TTL:            24 * time.Hour,
+			InFlightTTL:    getEnvAsDuration("REDIS_INFLIGHT_TTL", 10*time.Minute),
 			ConnectTimeout: 5 * time.Second,
+
+			ReconnectMinBackoff: getEnvAsDuration("REDIS_RECONNECT_MIN_BACKOFF", 1*time.Second),
+			ReconnectMaxBackoff: getEnvAsDuration("REDIS_RECONNECT_MAX_BACKOFF", 1*time.Minute),
+			HealthInterval:      getEnvAsDuration("REDIS_HEALTH_INTERVAL", 10*time.Second),
 		},
 		Webhook: WebhookConfig{
-			URL:              getEnv("WEBHOOK_URL", "https://webhook.site/your-unique-id"),
-			AuthKey:          getEnv("WEBHOOK_AUTH_KEY", "your-secret-key"),
-			Timeout:          getEnvAsDuration("WEBHOOK_TIMEOUT", 30*time.Second),
-			MaxRetryAttempts: 3,
-			RetryDelay:       1 * time.Second,
+			Provider:   getEnv("WEBHOOK_PROVIDER", constants.ProviderGeneric),
+			URL:        getEnv("WEBHOOK_URL", "https://webhook.site/your-unique-id"),
+			AuthKey:    getEnv("WEBHOOK_AUTH_KEY", "your-secret-key"),
+			Timeout:    getEnvAsDuration("WEBHOOK_TIMEOUT", 30*time.Second),
+			RetryDelay: 1 * time.Second,
+			RateLimit:  getEnvAsInt("WEBHOOK_RATE_LIMIT", 0),
+
+			SenderID:  getEnv("WEBHOOK_SENDER_ID", ""),
+			APIKey:    getEnv("WEBHOOK_API_KEY", ""),
+			APISecret: getEnv("WEBHOOK_API_SECRET", ""),
+
+			Method:                getEnv("WEBHOOK_METHOD", "POST"),
+			SuccessStatusCodes:    getEnvAsIntSlice("WEBHOOK_SUCCESS_STATUS_CODES", []int{202}),
+			ContentType:           getEnv("WEBHOOK_CONTENT_TYPE", "application/json"),
+			RequestTemplate:       getEnv("WEBHOOK_REQUEST_TEMPLATE", ""),
+			ResponseMessageIDPath: getEnv("WEBHOOK_RESPONSE_MESSAGE_ID_PATH", "messageId"),
+			ResponseStatusPath:    getEnv("WEBHOOK_RESPONSE_STATUS_PATH", "message"),
+
+			SigningSecret:         getEnv("WEBHOOK_SIGNING_SECRET", ""),
+			SigningSecretPrevious: getEnv("WEBHOOK_SIGNING_SECRET_PREVIOUS", ""),
+			SignatureHeader:       getEnv("WEBHOOK_SIGNATURE_HEADER", constants.HeaderSignature),
+			TimestampHeader:       getEnv("WEBHOOK_TIMESTAMP_HEADER", constants.HeaderTimestamp),
+
+			Auth: WebhookAuthConfig{
+				Type:          getEnv("WEBHOOK_AUTH_TYPE", constants.AuthTypeHeader),
+				HeaderName:    getEnv("WEBHOOK_AUTH_HEADER", constants.HeaderAuthKey),
+				Username:      getEnv("WEBHOOK_AUTH_USERNAME", ""),
+				Password:      getEnv("WEBHOOK_AUTH_PASSWORD", ""),
+				Token:         getEnv("WEBHOOK_AUTH_TOKEN", ""),
+				TokenURL:      getEnv("WEBHOOK_OAUTH_TOKEN_URL", ""),
+				ClientID:      getEnv("WEBHOOK_OAUTH_CLIENT_ID", ""),
+				ClientSecret:  getEnv("WEBHOOK_OAUTH_CLIENT_SECRET", ""),
+				Scopes:        getEnvAsSlice("WEBHOOK_OAUTH_SCOPES", nil),
+				RefreshBefore: getEnvAsDuration("WEBHOOK_OAUTH_REFRESH_BEFORE", 30*time.Second),
+			},
+		},
+		Email: EmailConfig{
+			Host:         getEnv("SMTP_HOST", ""),
+			Port:         getEnv("SMTP_PORT", "587"),
+			Username:     getEnv("SMTP_USERNAME", ""),
+			Password:     getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("SMTP_FROM", ""),
+			StartTLS:     getEnvAsBool("SMTP_STARTTLS", true),
+			Timeout:      getEnvAsDuration("SMTP_TIMEOUT", 30*time.Second),
+			MaxBodyBytes: getEnvAsInt("EMAIL_MAX_BODY_BYTES", 256*1024),
+		},
+		Push: PushConfig{
+			Webhook: WebhookConfig{
+				Provider:              constants.ChannelPush,
+				URL:                   getEnv("PUSH_WEBHOOK_URL", ""),
+				AuthKey:               getEnv("PUSH_WEBHOOK_AUTH_KEY", ""),
+				Timeout:               getEnvAsDuration("PUSH_WEBHOOK_TIMEOUT", 10*time.Second),
+				RetryDelay:            1 * time.Second,
+				SuccessStatusCodes:    getEnvAsIntSlice("PUSH_WEBHOOK_SUCCESS_STATUS_CODES", []int{200, 201, 202}),
+				ResponseMessageIDPath: getEnv("PUSH_WEBHOOK_RESPONSE_ID_PATH", "id"),
+				Auth: WebhookAuthConfig{
+					Type:       getEnv("PUSH_WEBHOOK_AUTH_TYPE", constants.AuthTypeHeader),
+					HeaderName: getEnv("PUSH_WEBHOOK_AUTH_HEADER", constants.HeaderAuthKey),
+					Token:      getEnv("PUSH_WEBHOOK_AUTH_TOKEN", ""),
+				},
+			},
+			MaxPayloadBytes: getEnvAsInt("PUSH_MAX_PAYLOAD_BYTES", 4096),
+		},
+		Retry: RetryConfig{
+			MaxAttempts:    getEnvAsInt("RETRY_MAX_ATTEMPTS", 4),
+			AttemptsPerRun: getEnvAsInt("RETRY_ATTEMPTS_PER_RUN", 2),
 		},
 		Scheduler: SchedulerConfig{
 			Interval:          getEnvAsDuration("SCHEDULER_INTERVAL", 2*time.Minute),
@@ -151,10 +348,47 @@ func Load() *Config {
 			RetryBaseDelay:    3 * time.Second,
 		},
 		Message: MessageConfig{
-			MaxLength:     getEnvAsInt("MESSAGE_MAX_LENGTH", 1000),
+			MaxSegments:   getEnvAsInt("MESSAGE_MAX_SEGMENTS", 6),
+			SplitLong:     getEnvAsBool("MESSAGE_SPLIT_LONG", false),
 			DefaultLimit:  10,
 			DefaultOffset: 0,
 		},
+		Attachment: AttachmentConfig{
+			Dir:           getEnv("ATTACHMENT_DIR", "./data/attachments"),
+			PublicBaseURL: getEnv("ATTACHMENT_PUBLIC_BASE_URL", "http://localhost:8080/media"),
+			MaxBytes:      int64(getEnvAsInt("ATTACHMENT_MAX_BYTES", 5*1024*1024)),
+			AllowedTypes:  getEnvAsSlice("ATTACHMENT_ALLOWED_TYPES", []string{"image/jpeg", "image/png", "image/gif", "application/pdf"}),
+		},
+		Cache: CacheConfig{
+			LocalSize: getEnvAsInt("CACHE_LOCAL_SIZE", 10000),
+			LocalTTL:  getEnvAsDuration("CACHE_LOCAL_TTL", 1*time.Hour),
+
+			ListEnabled:  getEnvAsBool("LIST_CACHE_ENABLED", false),
+			ListPageTTL:  getEnvAsDuration("LIST_CACHE_PAGE_TTL", 5*time.Second),
+			ListCountTTL: getEnvAsDuration("LIST_CACHE_COUNT_TTL", 5*time.Second),
+		},
+		Queue: QueueConfig{
+			Backend:      getEnv("QUEUE_BACKEND", constants.QueueBackendPostgres),
+			Stream:       getEnv("QUEUE_STREAM", "messages:queue"),
+			Group:        getEnv("QUEUE_GROUP", "senders"),
+			Consumer:     getEnv("QUEUE_CONSUMER", defaultConsumerName()),
+			Block:        getEnvAsDuration("QUEUE_BLOCK", 1*time.Second),
+			ClaimIdle:    getEnvAsDuration("QUEUE_CLAIM_IDLE", 5*time.Minute),
+			MaxLen:       int64(getEnvAsInt("QUEUE_MAX_LEN", 100000)),
+			FeedInterval: getEnvAsDuration("QUEUE_FEED_INTERVAL", 1*time.Second),
+			FeedBatch:    getEnvAsInt("QUEUE_FEED_BATCH", 500),
+		},
+		Retention: RetentionConfig{
+			Interval:    getEnvAsDuration("RETENTION_INTERVAL", 1*time.Hour),
+			Timeout:     5 * time.Minute,
+			Attempts:    getEnvAsDuration("ATTEMPT_RETENTION", 30*24*time.Hour),
+			Attachments: getEnvAsDuration("ATTACHMENT_RETENTION", 30*24*time.Hour),
+			BatchSize:   getEnvAsPositiveInt("RETENTION_BATCH_SIZE", 1000),
+
+			Messages:         getEnvAsDuration("MESSAGE_RETENTION", 0),
+			ArchiveBatchSize: getEnvAsPositiveInt("ARCHIVE_BATCH_SIZE", 100),
+			ArchiveDryRun:    getEnvAsBool("ARCHIVE_DRY_RUN", false),
+		},
 		AccessToken: getEnv("ACCESS_TOKEN", "your-access-token"),
 	}
 }
@@ -166,3 +400,26 @@ func (c *DatabaseConfig) GetDSN() string {
 		c.Host, c.User, c.Password, c.Name, c.Port,
 	)
 }
+
+// GetMySQLDSN returns the MySQL/MariaDB DSN. Times are parsed and stored in UTC.
+func (c *DatabaseConfig) GetMySQLDSN() string {
+	return fmt.Sprintf(
+		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true&loc=UTC",
+		c.User, c.Password, c.Host, c.Port, c.Name,
+	)
+}
+
+// GetSQLiteDSN returns the SQLite DSN. Foreign keys are enforced like on
+// Postgres, WAL and a busy timeout let the scheduler and API write concurrently.
+func (c *DatabaseConfig) GetSQLiteDSN() string {
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", c.Path)
+}
+
+// defaultConsumerName names the stream consumer after the host, so restarted
+// instances pick up their own pending entries
+func defaultConsumerName() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "sender"
+}
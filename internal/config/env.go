@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -39,3 +49,42 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var result []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	return defaultValue
+}
+
+func getEnvAsIntSlice(key string, defaultValue []int) []int {
+	values := getEnvAsSlice(key, nil)
+	if len(values) == 0 {
+		return defaultValue
+	}
+
+	result := make([]int, 0, len(values))
+	for _, value := range values {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return defaultValue
+		}
+		result = append(result, intValue)
+	}
+	return result
+}
+
+// getEnvAsPositiveInt is getEnvAsInt for values that must be at least 1, such
+// as batch sizes, falling back to defaultValue otherwise
+func getEnvAsPositiveInt(key string, defaultValue int) int {
+	if value := getEnvAsInt(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"insider-case/internal/constants"
+	"time"
+)
+
+// MockProviderConfig holds configuration of the local mock SMS provider
+type MockProviderConfig struct {
+	Env        string
+	Host       string
+	Port       string
+	AuthHeader string // Header carrying the auth key
+	AuthKey    string // Expected auth key, empty accepts every request
+
+	Latency LatencyConfig
+	Faults  FaultConfig
+
+	// Delivery receipts, disabled when CallbackURL is empty
+	CallbackURL   string
+	CallbackToken string // Sent as x-access-token, for the service's receipt endpoint
+	CallbackDelay time.Duration
+
+	Seed int64 // Random seed, 0 seeds from the clock
+}
+
+// LatencyConfig describes the response latency distribution of the mock provider
+type LatencyConfig struct {
+	Distribution string        // fixed, uniform or normal
+	Min          time.Duration // fixed value, or lower bound
+	Max          time.Duration // upper bound
+	Mean         time.Duration // mean of the normal distribution
+	StdDev       time.Duration // standard deviation of the normal distribution
+}
+
+// FaultConfig holds fault injection rates between 0 and 1
+type FaultConfig struct {
+	ErrorRate       float64       // 500 Internal Server Error
+	ThrottleRate    float64       // 429 Too Many Requests
+	UnavailableRate float64       // 503 Service Unavailable
+	MalformedRate   float64       // 202 with a body that is not valid JSON
+	RetryAfter      time.Duration // Retry-After sent with 429/503
+}
+
+// LoadMockProvider loads mock provider configuration from environment variables
+func LoadMockProvider() *MockProviderConfig {
+	if getEnv("ENV", "") == "local" {
+		_ = LoadEnvFile()
+	}
+	return &MockProviderConfig{
+		Env:        getEnv("ENV", "production"),
+		Host:       getEnv("MOCK_HOST", "0.0.0.0"),
+		Port:       getEnv("MOCK_PORT", "9090"),
+		AuthHeader: getEnv("MOCK_AUTH_HEADER", constants.HeaderAuthKey),
+		AuthKey:    getEnv("MOCK_AUTH_KEY", ""),
+		Latency: LatencyConfig{
+			Distribution: getEnv("MOCK_LATENCY_DISTRIBUTION", constants.LatencyFixed),
+			Min:          getEnvAsDuration("MOCK_LATENCY_MIN", 0),
+			Max:          getEnvAsDuration("MOCK_LATENCY_MAX", 0),
+			Mean:         getEnvAsDuration("MOCK_LATENCY_MEAN", 0),
+			StdDev:       getEnvAsDuration("MOCK_LATENCY_STDDEV", 0),
+		},
+		Faults: FaultConfig{
+			ErrorRate:       getEnvAsFloat("MOCK_ERROR_RATE", 0),
+			ThrottleRate:    getEnvAsFloat("MOCK_THROTTLE_RATE", 0),
+			UnavailableRate: getEnvAsFloat("MOCK_UNAVAILABLE_RATE", 0),
+			MalformedRate:   getEnvAsFloat("MOCK_MALFORMED_RATE", 0),
+			RetryAfter:      getEnvAsDuration("MOCK_RETRY_AFTER", 1*time.Second),
+		},
+		CallbackURL:   getEnv("MOCK_CALLBACK_URL", ""),
+		CallbackToken: getEnv("MOCK_CALLBACK_TOKEN", ""),
+		CallbackDelay: getEnvAsDuration("MOCK_CALLBACK_DELAY", 2*time.Second),
+		Seed:          int64(getEnvAsInt("MOCK_SEED", 0)),
+	}
+}
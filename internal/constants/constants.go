@@ -4,6 +4,57 @@ package constants
 const (
 	DBTypePostgres = "postgres"
 	DBTypeSQLite   = "sqlite"
+	DBTypeMySQL    = "mysql"
+)
+
+// Environments
+const (
+	EnvLocal      = "local"
+	EnvProduction = "production"
+)
+
+// Queue Backends
+const (
+	QueueBackendPostgres     = "postgres"
+	QueueBackendRedisStreams = "redis-streams"
+)
+
+// Webhook Provider Adapters
+const (
+	ProviderGeneric = "generic"
+	ProviderTwilio  = "twilio"
+	ProviderVonage  = "vonage"
+	ProviderBatch   = "batch"
+)
+
+// Delivery Channels
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Webhook Authentication Types
+const (
+	AuthTypeHeader = "header"
+	AuthTypeBasic  = "basic"
+	AuthTypeBearer = "bearer"
+	AuthTypeOAuth2 = "oauth2"
+	AuthTypeNone   = "none"
+)
+
+// Mock Provider Latency Distributions
+const (
+	LatencyFixed   = "fixed"
+	LatencyUniform = "uniform"
+	LatencyNormal  = "normal"
+)
+
+// Mock Provider Control Routes
+const (
+	MockControlBasePath = "/_mock"
+	MockRequestsPath    = "/requests"
+	MockFaultsPath      = "/faults"
 )
 
 // Default Database Values
@@ -28,12 +79,37 @@ const (
 	StatusSchedulerPath = "/statusScheduler"
 
 	// Message Routes
-	MessagesBasePath = "/messages"
-	SentMessagesPath = "/sent"
+	MessagesBasePath        = "/messages"
+	SentMessagesPath        = "/sent"
+	MessageByIDPath         = "/:id"
+	MessageByProviderIDPath = "/by-provider-id/:messageId"
+	AttachmentsPath         = "/:id/attachments"
+	DeliveryReceiptsPath    = "/receipts"
+
+	// Cache Routes
+	CacheBasePath  = "/cache"
+	CacheStatsPath = "/stats"
+	CacheFlushPath = "/flush"
+
+	// Archive Routes
+	ArchiveBasePath    = "/archive"
+	ArchiveStatsPath   = "/stats"
+	ArchiveRestorePath = "/messages/:id/restore"
+
+	// Uploaded media, fetched by providers without an access token
+	MediaPath = "/media/*key"
 
 	// HTTP Headers
 	HeaderAccessToken = "x-access-token"
 	HeaderAuthKey     = "x-ins-auth-key"
+	HeaderSignature   = "X-Signature"
+	HeaderTimestamp   = "X-Timestamp"
+	HeaderCache       = "X-Cache"
+	HeaderAge         = "Age"
+
+	// X-Cache header values
+	CacheHit  = "HIT"
+	CacheMiss = "MISS"
 )
 
 // Message Status
@@ -41,6 +117,7 @@ const (
 	MessageStatusQueued     = "queued"
 	MessageStatusProcessing = "processing"
 	MessageStatusSent       = "sent"
+	MessageStatusPartial    = "partially_sent"
 	MessageStatusDelivered  = "delivered"
 	MessageStatusFailed     = "failed"
 	MessageStatusCancelled  = "cancelled"
@@ -0,0 +1,184 @@
+package message
+
+import (
+	"context"
+	"insider-case/internal/pkg/logger"
+	"sync"
+	"time"
+)
+
+// ArchivableStatuses are the final statuses of messages that are archived
+// once they are older than the retention period
+var ArchivableStatuses = []MessageStatus{
+	MessageStatusSent,
+	MessageStatusDelivered,
+	MessageStatusPartial,
+	MessageStatusFailed,
+	MessageStatusCancelled,
+}
+
+// ArchivedMessage is a message moved out of the messages table, stored with
+// its parts and delivery attempts as JSON
+type ArchivedMessage struct {
+	ID         uint          `gorm:"primaryKey;autoIncrement:false" json:"id"` // ID of the archived message
+	Status     MessageStatus `gorm:"type:varchar(20);not null" json:"status"`
+	CreatedAt  time.Time     `gorm:"not null" json:"created_at"` // When the message was created
+	ArchivedAt time.Time     `gorm:"not null;index" json:"archived_at"`
+	Payload    string        `gorm:"not null" json:"-"` // JSON encoded ArchivePayload
+}
+
+// TableName specifies the table name for ArchivedMessage
+func (ArchivedMessage) TableName() string {
+	return "message_archives"
+}
+
+// ArchivePayload is the archived content of a message
+type ArchivePayload struct {
+	Message  *Message          `json:"message"`
+	Parts    []*MessagePart    `json:"parts,omitempty"`
+	Attempts []*MessageAttempt `json:"attempts,omitempty"`
+}
+
+// ArchiveRepository defines persistence of archived messages
+type ArchiveRepository interface {
+	// ArchiveMessagesBefore moves up to limit messages with one of statuses
+	// created before cutoff into the archive in one transaction, and returns
+	// how many were archived and deleted. Messages that still have
+	// attachments are left alone.
+	ArchiveMessagesBefore(ctx context.Context, cutoff time.Time, statuses []MessageStatus, limit int) (archived, deleted int64, err error)
+	// CountArchivable counts the messages ArchiveMessagesBefore would archive
+	CountArchivable(ctx context.Context, cutoff time.Time, statuses []MessageStatus) (int64, error)
+	// RestoreMessage moves an archived message back with its original ID
+	RestoreMessage(ctx context.Context, id uint) (*Message, error)
+}
+
+// ArchiveStats holds archiver counters
+type ArchiveStats struct {
+	DryRun    bool       `json:"dry_run"`
+	Runs      uint64     `json:"runs"`
+	Archived  uint64     `json:"archived"` // Messages copied into the archive
+	Deleted   uint64     `json:"deleted"`  // Messages deleted after archiving
+	Restored  uint64     `json:"restored"` // Messages moved back from the archive
+	Eligible  int64      `json:"eligible"` // Messages the last dry run would have archived
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+}
+
+// Archiver moves messages in a final status out of the messages table once
+// they are older than the retention period
+type Archiver struct {
+	repo      ArchiveRepository
+	service   *Service
+	retention time.Duration
+	batchSize int
+	dryRun    bool
+
+	mu    sync.Mutex
+	stats ArchiveStats
+}
+
+// NewArchiver creates a new Archiver. In dry run mode it only counts the
+// messages it would archive. The list cache of service is flushed after
+// messages are archived or restored.
+func NewArchiver(repo ArchiveRepository, service *Service, retention time.Duration, batchSize int, dryRun bool) *Archiver {
+	return &Archiver{
+		repo:      repo,
+		service:   service,
+		retention: retention,
+		batchSize: batchSize,
+		dryRun:    dryRun,
+		stats:     ArchiveStats{DryRun: dryRun},
+	}
+}
+
+func (a *Archiver) Name() string {
+	return "message_archival"
+}
+
+// Run archives expired messages in batches, each in its own transaction to
+// keep locks short
+func (a *Archiver) Run(ctx context.Context) error {
+	if a.retention <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-a.retention)
+	if a.dryRun {
+		eligible, err := a.repo.CountArchivable(ctx, cutoff, ArchivableStatuses)
+		if err != nil {
+			return &ErrRepository{Operation: "count archivable messages", Err: err}
+		}
+		a.record(func(stats *ArchiveStats) { stats.Eligible = eligible })
+		logger.Info("Dry run, messages not archived", "eligible", eligible, "cutoff", cutoff)
+		return nil
+	}
+
+	var archived, deleted int64
+	defer func() {
+		a.record(func(stats *ArchiveStats) {
+			stats.Archived += uint64(archived)
+			stats.Deleted += uint64(deleted)
+		})
+		if archived > 0 {
+			logger.Info("Archived expired messages", "archived", archived, "deleted", deleted, "cutoff", cutoff)
+			a.flushListCache(ctx)
+		}
+	}()
+
+	for {
+		batchArchived, batchDeleted, err := a.repo.ArchiveMessagesBefore(ctx, cutoff, ArchivableStatuses, a.batchSize)
+		if err != nil {
+			return &ErrRepository{Operation: "archive expired messages", Err: err}
+		}
+		archived += batchArchived
+		deleted += batchDeleted
+		if batchArchived < int64(a.batchSize) {
+			return nil
+		}
+	}
+}
+
+// Restore moves an archived message back into the messages table
+func (a *Archiver) Restore(ctx context.Context, id uint) (*Message, error) {
+	msg, err := a.repo.RestoreMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.stats.Restored++
+	a.mu.Unlock()
+
+	logger.Info("Restored archived message", "message_id", id, "status", msg.Status)
+	if a.service != nil && msg.Status == MessageStatusSent {
+		a.service.invalidateSent(ctx, msg)
+	}
+	return msg, nil
+}
+
+// Stats returns a snapshot of the counters
+func (a *Archiver) Stats() ArchiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stats
+}
+
+// record updates the counters at the end of a run
+func (a *Archiver) record(update func(stats *ArchiveStats)) {
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stats.Runs++
+	a.stats.LastRunAt = &now
+	update(&a.stats)
+}
+
+// flushListCache drops cached sent message pages, which may list archived messages
+func (a *Archiver) flushListCache(ctx context.Context) {
+	if a.service == nil {
+		return
+	}
+	if err := a.service.FlushListCache(ctx); err != nil {
+		logger.Warn("Failed to flush list cache after archiving", "error", err)
+	}
+}
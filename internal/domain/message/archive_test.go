@@ -0,0 +1,88 @@
+package message
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveRepo is an ArchiveRepository holding pending message IDs
+type archiveRepo struct {
+	pending  []uint
+	archived []uint
+	batches  int
+}
+
+func (r *archiveRepo) ArchiveMessagesBefore(ctx context.Context, cutoff time.Time, statuses []MessageStatus, limit int) (int64, int64, error) {
+	r.batches++
+	n := limit
+	if n > len(r.pending) {
+		n = len(r.pending)
+	}
+	r.archived = append(r.archived, r.pending[:n]...)
+	r.pending = r.pending[n:]
+	return int64(n), int64(n), nil
+}
+
+func (r *archiveRepo) CountArchivable(ctx context.Context, cutoff time.Time, statuses []MessageStatus) (int64, error) {
+	return int64(len(r.pending)), nil
+}
+
+func (r *archiveRepo) RestoreMessage(ctx context.Context, id uint) (*Message, error) {
+	for i, archivedID := range r.archived {
+		if archivedID == id {
+			r.archived = append(r.archived[:i], r.archived[i+1:]...)
+			return &Message{ID: id, Status: MessageStatusSent}, nil
+		}
+	}
+	return nil, ErrArchivedNotFound
+}
+
+func TestArchiver_ArchivesInBatches(t *testing.T) {
+	repo := &archiveRepo{pending: []uint{1, 2, 3, 4, 5}}
+	archiver := NewArchiver(repo, nil, 24*time.Hour, 2, false)
+
+	require.NoError(t, archiver.Run(context.Background()))
+	assert.Empty(t, repo.pending)
+	assert.Equal(t, 3, repo.batches)
+
+	stats := archiver.Stats()
+	assert.Equal(t, uint64(1), stats.Runs)
+	assert.Equal(t, uint64(5), stats.Archived)
+	assert.Equal(t, uint64(5), stats.Deleted)
+	assert.NotNil(t, stats.LastRunAt)
+
+	msg, err := archiver.Restore(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), msg.ID)
+	assert.Equal(t, uint64(1), archiver.Stats().Restored)
+
+	_, err = archiver.Restore(context.Background(), 3)
+	assert.ErrorIs(t, err, ErrArchivedNotFound)
+}
+
+func TestArchiver_DryRunOnlyCounts(t *testing.T) {
+	repo := &archiveRepo{pending: []uint{1, 2, 3}}
+	archiver := NewArchiver(repo, nil, 24*time.Hour, 2, true)
+
+	require.NoError(t, archiver.Run(context.Background()))
+	assert.Len(t, repo.pending, 3)
+	assert.Zero(t, repo.batches)
+
+	stats := archiver.Stats()
+	assert.True(t, stats.DryRun)
+	assert.Equal(t, int64(3), stats.Eligible)
+	assert.Zero(t, stats.Archived)
+}
+
+func TestArchiver_DisabledWithoutRetention(t *testing.T) {
+	repo := &archiveRepo{pending: []uint{1}}
+	archiver := NewArchiver(repo, nil, 0, 10, false)
+
+	require.NoError(t, archiver.Run(context.Background()))
+	assert.Len(t, repo.pending, 1)
+	assert.Zero(t, archiver.Stats().Runs)
+}
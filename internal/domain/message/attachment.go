@@ -0,0 +1,310 @@
+package message
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Attachment is a media file sent with a message, either referenced by an
+// external URL or uploaded to the blob store
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	MessageID   uint      `gorm:"not null;index" json:"message_id"`
+	URL         string    `gorm:"type:text;not null" json:"url"`    // URL the provider fetches the media from
+	StorageKey  string    `gorm:"type:varchar(255);index" json:"-"` // Blob store key, empty for external URLs
+	FileName    string    `gorm:"type:varchar(255)" json:"file_name,omitempty"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	SizeBytes   int64     `gorm:"not null;default:0" json:"size_bytes,omitempty"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for Attachment
+func (Attachment) TableName() string {
+	return "attachments"
+}
+
+// AttachmentRepository defines persistence of attachments
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *Attachment) error
+	GetAttachments(ctx context.Context, messageID uint) ([]*Attachment, error)
+	GetAttachmentByKey(ctx context.Context, key string) (*Attachment, error)
+	GetAttachmentsBefore(ctx context.Context, cutoff time.Time, limit int) ([]*Attachment, error)
+	DeleteAttachment(ctx context.Context, id uint) error
+}
+
+// BlobStore stores uploaded attachment content
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentPolicy holds the validation rules of attachments
+type AttachmentPolicy struct {
+	AllowedTypes []string // Allowed MIME types
+	MaxBytes     int64
+}
+
+func (p AttachmentPolicy) allows(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range p.AllowedTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachmentService attaches media to queued messages
+type AttachmentService struct {
+	repo          AttachmentRepository
+	messages      Repository
+	store         BlobStore
+	policy        AttachmentPolicy
+	publicBaseURL string
+}
+
+// NewAttachmentService creates a new AttachmentService. Uploaded blobs are
+// exposed to providers under publicBaseURL.
+func NewAttachmentService(
+	repo AttachmentRepository,
+	messages Repository,
+	store BlobStore,
+	policy AttachmentPolicy,
+	publicBaseURL string,
+) *AttachmentService {
+	return &AttachmentService{
+		repo:          repo,
+		messages:      messages,
+		store:         store,
+		policy:        policy,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+	}
+}
+
+// AttachURL attaches media hosted elsewhere. The declared content type and
+// size are validated, the URL itself is not fetched.
+func (s *AttachmentService) AttachURL(ctx context.Context, messageID uint, rawURL, contentType string, sizeBytes int64) (*Attachment, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidAttachment)
+	}
+	if err := s.validate(contentType, sizeBytes); err != nil {
+		return nil, err
+	}
+	if err := s.requireQueued(ctx, messageID); err != nil {
+		return nil, err
+	}
+
+	attachment := &Attachment{
+		MessageID:   messageID,
+		URL:         rawURL,
+		FileName:    fileNameFromPath(parsed.Path),
+		ContentType: contentType,
+		SizeBytes:   sizeBytes,
+	}
+	if err := s.repo.CreateAttachment(ctx, attachment); err != nil {
+		return nil, &ErrRepository{Operation: "create attachment", Err: err}
+	}
+	return attachment, nil
+}
+
+// Upload stores r in the blob store and attaches it. The content type is
+// sniffed from the content rather than trusted from the client.
+func (s *AttachmentService) Upload(ctx context.Context, messageID uint, fileName string, r io.Reader) (*Attachment, error) {
+	if err := s.requireQueued(ctx, messageID); err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReaderSize(r, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	contentType := http.DetectContentType(head)
+	if err := s.validate(contentType, 0); err != nil {
+		return nil, err
+	}
+
+	key := newStorageKey(messageID, contentType)
+	counter := &countingReader{r: io.LimitReader(buffered, s.policy.MaxBytes+1)}
+	if err := s.store.Put(ctx, key, counter); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if counter.n > s.policy.MaxBytes {
+		_ = s.store.Delete(ctx, key)
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrInvalidAttachment, s.policy.MaxBytes)
+	}
+
+	attachment := &Attachment{
+		MessageID:   messageID,
+		URL:         s.publicBaseURL + "/" + key,
+		StorageKey:  key,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   counter.n,
+	}
+	if err := s.repo.CreateAttachment(ctx, attachment); err != nil {
+		_ = s.store.Delete(ctx, key)
+		return nil, &ErrRepository{Operation: "create attachment", Err: err}
+	}
+	return attachment, nil
+}
+
+// List returns the attachments of a message
+func (s *AttachmentService) List(ctx context.Context, messageID uint) ([]*Attachment, error) {
+	attachments, err := s.repo.GetAttachments(ctx, messageID)
+	if err != nil {
+		return nil, &ErrRepository{Operation: "get attachments", Err: err}
+	}
+	return attachments, nil
+}
+
+// Open returns the content of an uploaded attachment by its storage key
+func (s *AttachmentService) Open(ctx context.Context, key string) (*Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetAttachmentByKey(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.store.Open(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// Media returns the attachments of a message in provider payload form
+func (s *AttachmentService) Media(ctx context.Context, messageID uint) ([]WebhookMedia, error) {
+	attachments, err := s.List(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	media := make([]WebhookMedia, len(attachments))
+	for i, attachment := range attachments {
+		media[i] = WebhookMedia{
+			URL:         attachment.URL,
+			ContentType: attachment.ContentType,
+		}
+	}
+	return media, nil
+}
+
+// Delete removes an attachment and its stored content
+func (s *AttachmentService) Delete(ctx context.Context, attachment *Attachment) error {
+	if attachment.StorageKey != "" {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return fmt.Errorf("failed to delete attachment content: %w", err)
+		}
+	}
+	return s.repo.DeleteAttachment(ctx, attachment.ID)
+}
+
+func (s *AttachmentService) validate(contentType string, sizeBytes int64) error {
+	if !s.policy.allows(contentType) {
+		return fmt.Errorf("%w: content type %q is not allowed", ErrInvalidAttachment, contentType)
+	}
+	if sizeBytes < 0 || sizeBytes > s.policy.MaxBytes {
+		return fmt.Errorf("%w: exceeds %d bytes", ErrInvalidAttachment, s.policy.MaxBytes)
+	}
+	return nil
+}
+
+// requireQueued allows attaching media only until the message is picked up
+func (s *AttachmentService) requireQueued(ctx context.Context, messageID uint) error {
+	msg, err := s.messages.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if msg.Status != MessageStatusQueued {
+		return ErrInvalidMessageStatus
+	}
+	return nil
+}
+
+// AttachmentPruner deletes attachments older than the retention period
+type AttachmentPruner struct {
+	service   *AttachmentService
+	retention time.Duration
+	batchSize int
+}
+
+// NewAttachmentPruner creates a new AttachmentPruner
+func NewAttachmentPruner(service *AttachmentService, retention time.Duration, batchSize int) *AttachmentPruner {
+	return &AttachmentPruner{
+		service:   service,
+		retention: retention,
+		batchSize: batchSize,
+	}
+}
+
+func (p *AttachmentPruner) Name() string {
+	return "attachment_retention"
+}
+
+// Run deletes expired attachments and their stored content
+func (p *AttachmentPruner) Run(ctx context.Context) error {
+	if p.retention <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-p.retention)
+	for {
+		attachments, err := p.service.repo.GetAttachmentsBefore(ctx, cutoff, p.batchSize)
+		if err != nil {
+			return &ErrRepository{Operation: "get expired attachments", Err: err}
+		}
+		for _, attachment := range attachments {
+			if err := p.service.Delete(ctx, attachment); err != nil {
+				return err
+			}
+		}
+		if len(attachments) < p.batchSize {
+			return nil
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func newStorageKey(messageID uint, contentType string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	ext := ""
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return fmt.Sprintf("attachments/%d/%s%s", messageID, hex.EncodeToString(b), ext)
+}
+
+func fileNameFromPath(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
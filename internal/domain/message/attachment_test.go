@@ -0,0 +1,161 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// attachmentRepo is an in-memory AttachmentRepository
+type attachmentRepo struct {
+	attachments []*Attachment
+}
+
+func (r *attachmentRepo) CreateAttachment(ctx context.Context, attachment *Attachment) error {
+	attachment.ID = uint(len(r.attachments) + 1)
+	r.attachments = append(r.attachments, attachment)
+	return nil
+}
+
+func (r *attachmentRepo) GetAttachments(ctx context.Context, messageID uint) ([]*Attachment, error) {
+	var result []*Attachment
+	for _, attachment := range r.attachments {
+		if attachment.MessageID == messageID {
+			result = append(result, attachment)
+		}
+	}
+	return result, nil
+}
+
+func (r *attachmentRepo) GetAttachmentByKey(ctx context.Context, key string) (*Attachment, error) {
+	for _, attachment := range r.attachments {
+		if attachment.StorageKey == key {
+			return attachment, nil
+		}
+	}
+	return nil, ErrAttachmentNotFound
+}
+
+func (r *attachmentRepo) GetAttachmentsBefore(ctx context.Context, cutoff time.Time, limit int) ([]*Attachment, error) {
+	return nil, nil
+}
+
+func (r *attachmentRepo) DeleteAttachment(ctx context.Context, id uint) error {
+	return nil
+}
+
+// memoryStore is an in-memory BlobStore
+type memoryStore map[string][]byte
+
+func (s memoryStore) Put(ctx context.Context, key string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	s[key] = content
+	return err
+}
+
+func (s memoryStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s[key])), nil
+}
+
+func (s memoryStore) Delete(ctx context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+// messageLookupRepo serves GetMessageByID from a partsRepo
+type messageLookupRepo struct {
+	*partsRepo
+}
+
+func (r messageLookupRepo) GetMessageByID(ctx context.Context, id uint) (*Message, error) {
+	if r.msg.ID != id {
+		return nil, ErrMessageNotFound
+	}
+	return r.msg, nil
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newAttachmentService(msg *Message) (*AttachmentService, *attachmentRepo, memoryStore) {
+	repo := &attachmentRepo{}
+	store := memoryStore{}
+	policy := AttachmentPolicy{AllowedTypes: []string{"image/png", "application/pdf"}, MaxBytes: 64}
+	return NewAttachmentService(repo, messageLookupRepo{&partsRepo{msg: msg}}, store, policy, "http://localhost/media/"), repo, store
+}
+
+func TestAttachmentService_UploadSniffsContentType(t *testing.T) {
+	service, _, store := newAttachmentService(longMessage())
+
+	attachment, err := service.Upload(context.Background(), 300, "photo.jpg", bytes.NewReader(pngHeader))
+	require.NoError(t, err)
+
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, int64(len(pngHeader)), attachment.SizeBytes)
+	assert.Equal(t, "http://localhost/media/"+attachment.StorageKey, attachment.URL)
+	assert.Equal(t, pngHeader, store[attachment.StorageKey])
+}
+
+func TestAttachmentService_RejectsInvalidUploads(t *testing.T) {
+	service, repo, store := newAttachmentService(longMessage())
+
+	_, err := service.Upload(context.Background(), 300, "notes.txt", strings.NewReader("plain text"))
+	assert.True(t, errors.Is(err, ErrInvalidAttachment))
+
+	oversized := append(append([]byte{}, pngHeader...), make([]byte, 64)...)
+	_, err = service.Upload(context.Background(), 300, "large.png", bytes.NewReader(oversized))
+	assert.True(t, errors.Is(err, ErrInvalidAttachment))
+
+	assert.Empty(t, repo.attachments)
+	assert.Empty(t, store)
+}
+
+func TestAttachmentService_AttachURL(t *testing.T) {
+	service, _, _ := newAttachmentService(longMessage())
+
+	attachment, err := service.AttachURL(context.Background(), 300, "https://cdn.example.com/files/report.pdf", "application/pdf", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", attachment.FileName)
+	assert.Empty(t, attachment.StorageKey)
+
+	_, err = service.AttachURL(context.Background(), 300, "ftp://cdn.example.com/report.pdf", "application/pdf", 10)
+	assert.True(t, errors.Is(err, ErrInvalidAttachment))
+
+	_, err = service.AttachURL(context.Background(), 300, "https://cdn.example.com/video.mp4", "video/mp4", 10)
+	assert.True(t, errors.Is(err, ErrInvalidAttachment))
+
+	_, err = service.AttachURL(context.Background(), 1, "https://cdn.example.com/report.pdf", "application/pdf", 10)
+	assert.True(t, errors.Is(err, ErrMessageNotFound))
+}
+
+func TestAttachmentService_RequiresQueuedMessage(t *testing.T) {
+	msg := longMessage()
+	msg.Status = MessageStatusSent
+	service, _, _ := newAttachmentService(msg)
+
+	_, err := service.Upload(context.Background(), 300, "photo.png", bytes.NewReader(pngHeader))
+	assert.True(t, errors.Is(err, ErrInvalidMessageStatus))
+}
+
+func TestService_SendsMediaWithFirstPart(t *testing.T) {
+	repo := &partsRepo{msg: longMessage()}
+	webhook := &partsWebhook{}
+	attachments, _, _ := newAttachmentService(repo.msg)
+	_, err := attachments.AttachURL(context.Background(), 300, "https://cdn.example.com/a.png", "image/png", 10)
+	require.NoError(t, err)
+
+	service := newPartsService(repo, webhook)
+	service.EnableAttachments(attachments)
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+
+	require.Len(t, webhook.requests, 3)
+	assert.Equal(t, []WebhookMedia{{URL: "https://cdn.example.com/a.png", ContentType: "image/png"}}, webhook.requests[0].Media)
+	assert.Empty(t, webhook.requests[1].Media)
+	assert.Empty(t, webhook.requests[2].Media)
+}
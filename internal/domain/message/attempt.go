@@ -0,0 +1,46 @@
+package message
+
+import (
+	"time"
+)
+
+// MessageAttempt is the persisted record of a single provider call, kept as
+// evidence for disputes with the provider
+type MessageAttempt struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	MessageID     uint      `gorm:"not null;index" json:"message_id"`
+	PartNumber    int       `gorm:"not null;default:0" json:"part_number,omitempty"` // 0 unless the message was split
+	Provider      string    `gorm:"type:varchar(50)" json:"provider"`
+	AttemptNumber int       `gorm:"not null" json:"attempt_number"`
+	RequestedAt   time.Time `gorm:"not null" json:"requested_at"`
+	LatencyMs     int64     `gorm:"not null;default:0" json:"latency_ms"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	ResponseBody  string    `gorm:"type:text" json:"response_body,omitempty"` // Truncated
+	Error         string    `gorm:"type:text" json:"error,omitempty"`
+	Throttled     bool      `gorm:"not null;default:false" json:"throttled"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for MessageAttempt
+func (MessageAttempt) TableName() string {
+	return "message_attempts"
+}
+
+// newMessageAttempt converts a finished provider call into its persisted form
+func newMessageAttempt(messageID uint, partNumber int, attempt *Attempt) *MessageAttempt {
+	record := &MessageAttempt{
+		MessageID:     messageID,
+		PartNumber:    partNumber,
+		Provider:      attempt.Provider,
+		AttemptNumber: attempt.Number,
+		RequestedAt:   attempt.RequestedAt,
+		LatencyMs:     attempt.Latency.Milliseconds(),
+		StatusCode:    attempt.StatusCode,
+		ResponseBody:  attempt.ResponseBody,
+		Throttled:     attempt.Throttled,
+	}
+	if attempt.Err != nil {
+		record.Error = attempt.Err.Error()
+	}
+	return record
+}
@@ -0,0 +1,126 @@
+package message
+
+import (
+	"fmt"
+	"insider-case/internal/constants"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// ChannelValidator checks that a message can be delivered through its channel
+type ChannelValidator interface {
+	Validate(msg *Message) error
+}
+
+// Channel is a delivery channel: the sender used for it and its validation rules
+type Channel struct {
+	Name      string
+	Sender    WebhookClient
+	Validator ChannelValidator
+}
+
+// ChannelRegistry maps channel names to their senders
+type ChannelRegistry struct {
+	channels map[string]*Channel
+}
+
+// NewChannelRegistry creates a new, empty ChannelRegistry
+func NewChannelRegistry() *ChannelRegistry {
+	return &ChannelRegistry{
+		channels: make(map[string]*Channel),
+	}
+}
+
+// Register adds or replaces the sender and validator of a channel
+func (r *ChannelRegistry) Register(name string, sender WebhookClient, validator ChannelValidator) {
+	r.channels[name] = &Channel{
+		Name:      name,
+		Sender:    sender,
+		Validator: validator,
+	}
+}
+
+// Lookup returns the channel registered under name. Messages without a
+// channel are SMS, as they were before channels existed.
+func (r *ChannelRegistry) Lookup(name string) (*Channel, error) {
+	if name == "" {
+		name = constants.ChannelSMS
+	}
+	channel, ok := r.channels[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedChannel, name)
+	}
+	return channel, nil
+}
+
+// Names returns the registered channel names
+func (r *ChannelRegistry) Names() []string {
+	names := make([]string, 0, len(r.channels))
+	for name := range r.channels {
+		names = append(names, name)
+	}
+	return names
+}
+
+var phoneNumberPattern = regexp.MustCompile(`^\+?[1-9][0-9]{6,14}$`)
+
+// SMSValidator requires a phone number and content within MaxSegments SMS segments
+type SMSValidator struct {
+	MaxSegments int
+}
+
+func (v *SMSValidator) Validate(msg *Message) error {
+	if !phoneNumberPattern.MatchString(msg.To) {
+		return fmt.Errorf("%w: %q is not a phone number", ErrInvalidRecipient, msg.To)
+	}
+
+	analysis := msg.Analyze()
+	if analysis.Segments == 0 {
+		return ErrInvalidContent
+	}
+	if analysis.Segments > v.MaxSegments {
+		return &ErrTooManySegments{
+			Encoding:    msg.Encoding,
+			Segments:    analysis.Segments,
+			MaxSegments: v.MaxSegments,
+		}
+	}
+	return nil
+}
+
+// EmailValidator requires an email address, a subject and a body within MaxBodyBytes
+type EmailValidator struct {
+	MaxBodyBytes int
+}
+
+func (v *EmailValidator) Validate(msg *Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("%w: %q is not an email address", ErrInvalidRecipient, msg.To)
+	}
+	if strings.TrimSpace(msg.Subject) == "" {
+		return fmt.Errorf("%w: email subject is required", ErrInvalidContent)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("%w: email subject must be a single line", ErrInvalidContent)
+	}
+	if msg.Content == "" || len(msg.Content) > v.MaxBodyBytes {
+		return fmt.Errorf("%w: email body must be between 1 and %d bytes", ErrInvalidContent, v.MaxBodyBytes)
+	}
+	return nil
+}
+
+// PushValidator requires a device token and a payload within MaxPayloadBytes
+type PushValidator struct {
+	MaxPayloadBytes int
+}
+
+func (v *PushValidator) Validate(msg *Message) error {
+	if msg.To == "" || strings.ContainsAny(msg.To, " \t\r\n") {
+		return fmt.Errorf("%w: push device token is required", ErrInvalidRecipient)
+	}
+	if msg.Content == "" || len(msg.Subject)+len(msg.Content) > v.MaxPayloadBytes {
+		return fmt.Errorf("%w: push title and body must be between 1 and %d bytes", ErrInvalidContent, v.MaxPayloadBytes)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package message
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelRegistry_Lookup(t *testing.T) {
+	registry := NewChannelRegistry()
+	registry.Register("sms", nil, &SMSValidator{MaxSegments: 1})
+
+	channel, err := registry.Lookup("")
+	require.NoError(t, err)
+	assert.Equal(t, "sms", channel.Name)
+
+	_, err = registry.Lookup("fax")
+	assert.ErrorIs(t, err, ErrUnsupportedChannel)
+}
+
+func TestChannelValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator ChannelValidator
+		msg       *Message
+		expected  error
+	}{
+		{"sms ok", &SMSValidator{MaxSegments: 1}, &Message{To: "+905551111111", Content: "hi"}, nil},
+		{"sms bad number", &SMSValidator{MaxSegments: 1}, &Message{To: "user@example.com", Content: "hi"}, ErrInvalidRecipient},
+		{"sms empty", &SMSValidator{MaxSegments: 1}, &Message{To: "+905551111111"}, ErrInvalidContent},
+		{"email ok", &EmailValidator{MaxBodyBytes: 100}, &Message{To: "user@example.com", Subject: "Hi", Content: "hi"}, nil},
+		{"email bad address", &EmailValidator{MaxBodyBytes: 100}, &Message{To: "+905551111111", Subject: "Hi", Content: "hi"}, ErrInvalidRecipient},
+		{"email no subject", &EmailValidator{MaxBodyBytes: 100}, &Message{To: "user@example.com", Content: "hi"}, ErrInvalidContent},
+		{"email header injection", &EmailValidator{MaxBodyBytes: 100}, &Message{To: "user@example.com", Subject: "Hi\r\nBcc: x@y.z", Content: "hi"}, ErrInvalidContent},
+		{"email too large", &EmailValidator{MaxBodyBytes: 10}, &Message{To: "user@example.com", Subject: "Hi", Content: strings.Repeat("a", 11)}, ErrInvalidContent},
+		{"push ok", &PushValidator{MaxPayloadBytes: 100}, &Message{To: "device-token", Subject: "Title", Content: "body"}, nil},
+		{"push no token", &PushValidator{MaxPayloadBytes: 100}, &Message{Content: "body"}, ErrInvalidRecipient},
+		{"push too large", &PushValidator{MaxPayloadBytes: 10}, &Message{To: "device-token", Subject: "Title", Content: "long body"}, ErrInvalidContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Validate(tt.msg)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+// channelWebhook records the requests it was asked to send
+type channelWebhook struct {
+	requests []*WebhookRequest
+}
+
+func (w *channelWebhook) SendMessage(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
+	w.requests = append(w.requests, req)
+	return &WebhookResponse{Message: "Accepted", MessageID: "id-1"}, nil
+}
+
+func TestService_RoutesMessagesByChannel(t *testing.T) {
+	smsSender := &channelWebhook{}
+	emailSender := &channelWebhook{}
+	repo := &partsRepo{msg: &Message{ID: 1, Channel: "email", To: "user@example.com", Subject: "Receipt", Content: "Thanks", Status: MessageStatusQueued}}
+
+	service := NewService(repo, nil, smsSender, 1, 6, false, NewRetryPolicy(2, 1), time.Second)
+	service.RegisterChannel("email", emailSender, &EmailValidator{MaxBodyBytes: 1024})
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Empty(t, smsSender.requests)
+	require.Len(t, emailSender.requests, 1)
+	assert.Equal(t, "Receipt", emailSender.requests[0].Subject)
+	assert.Equal(t, MessageStatusSent, repo.msg.Status)
+}
+
+func TestService_FailsUnsupportedChannel(t *testing.T) {
+	repo := &partsRepo{msg: &Message{ID: 1, Channel: "push", To: "device-token", Content: "hi", Status: MessageStatusQueued}}
+	service := NewService(repo, nil, &channelWebhook{}, 1, 6, false, NewRetryPolicy(2, 1), time.Second)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, MessageStatusFailed, repo.msg.Status)
+}
@@ -1,7 +1,8 @@
 package message
 
 import (
-	"unicode/utf8"
+	"insider-case/internal/pkg/sms"
+	"time"
 )
 
 // DTOs (Data Transfer Objects) for API requests and responses
@@ -13,7 +14,7 @@ type SendMessageRequest struct {
 }
 
 // Validate validates the SendMessageRequest
-func (r *SendMessageRequest) Validate(maxLength int) error {
+func (r *SendMessageRequest) Validate(maxSegments int) error {
 	if r.To == "" {
 		return ErrToFieldRequired
 	}
@@ -22,17 +23,27 @@ func (r *SendMessageRequest) Validate(maxLength int) error {
 		return ErrContentFieldRequired
 	}
 
-	contentLength := utf8.RuneCountInString(r.Content)
-	if contentLength > maxLength {
-		return &ErrContentLengthExceeded{
-			Length:    contentLength,
-			MaxLength: maxLength,
+	analysis := sms.Analyze(r.Content)
+	if analysis.Segments > maxSegments {
+		return &ErrTooManySegments{
+			Encoding:    string(analysis.Encoding),
+			Segments:    analysis.Segments,
+			MaxSegments: maxSegments,
 		}
 	}
 
 	return nil
 }
 
+// DeliveryReceiptRequest is the delivery receipt a provider posts for a
+// message it accepted, keyed by the messageId it returned
+type DeliveryReceiptRequest struct {
+	MessageID   string    `json:"messageId" binding:"required"`
+	To          string    `json:"to"`
+	Status      string    `json:"status" binding:"required"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+}
+
 // MessageResponse represents a message in API responses
 type MessageResponse struct {
 	ID        uint   `json:"id"`
@@ -51,3 +62,25 @@ type SentMessagesResponse struct {
 	Limit    int                `json:"limit"`
 	Offset   int                `json:"offset"`
 }
+
+// ProviderLookupResponse represents a message found by its provider messageId
+type ProviderLookupResponse struct {
+	Message *Message   `json:"message"`
+	Cached  bool       `json:"cached"`            // Resolved through the Redis messageId cache
+	SentAt  *time.Time `json:"sent_at,omitempty"` // Sending time stored in the cache
+}
+
+// MessageDetailResponse represents a message with its delivery attempts
+type MessageDetailResponse struct {
+	Message  *Message          `json:"message"`
+	Parts    []*MessagePart    `json:"parts,omitempty"`
+	Attempts []*MessageAttempt `json:"attempts"`
+}
+
+// SentMessagesPage is a page of sent messages with its cache metadata
+type SentMessagesPage struct {
+	Messages []*Message
+	Total    int64
+	Cached   bool      // Page and count were both served from the list cache
+	CachedAt time.Time // When the older of the two was cached, zero on a miss
+}
@@ -3,6 +3,7 @@ package message
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Domain-specific errors
@@ -11,6 +12,13 @@ var (
 	ErrInvalidMessageStatus = errors.New("invalid message status")
 	ErrMessageAlreadySent   = errors.New("message already sent")
 	ErrInvalidContent       = errors.New("message content is invalid")
+	ErrInvalidRecipient     = errors.New("message recipient is invalid")
+	ErrUnsupportedChannel   = errors.New("unsupported delivery channel")
+	ErrInvalidAttachment    = errors.New("attachment is invalid")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrArchivedNotFound     = errors.New("archived message not found")
+	ErrMessageExists        = errors.New("message already exists")
+	ErrDeliveryInFlight     = errors.New("earlier delivery is still in flight")
 	ErrSchedulerRunning     = errors.New("scheduler is already running")
 	ErrSchedulerNotRunning  = errors.New("scheduler is not running")
 	ErrSchedulerTimeout     = errors.New("scheduler shutdown timeout")
@@ -20,14 +28,40 @@ var (
 	ErrContentFieldRequired = errors.New("content field is required")
 )
 
-// ErrContentLengthExceeded represents content length validation error
-type ErrContentLengthExceeded struct {
-	Length    int
-	MaxLength int
+// ErrTooManySegments represents content that would be billed as more SMS segments than allowed
+type ErrTooManySegments struct {
+	Encoding    string
+	Segments    int
+	MaxSegments int
 }
 
-func (e *ErrContentLengthExceeded) Error() string {
-	return fmt.Sprintf("content length (%d) exceeds maximum allowed length (%d)", e.Length, e.MaxLength)
+func (e *ErrTooManySegments) Error() string {
+	return fmt.Sprintf("content needs %d %s segments, maximum allowed is %d", e.Segments, e.Encoding, e.MaxSegments)
+}
+
+// ErrPartialDelivery reports a split message of which some parts could not be delivered
+type ErrPartialDelivery struct {
+	Total  int
+	Sent   int
+	Failed []int // Part numbers that failed permanently
+}
+
+func (e *ErrPartialDelivery) Error() string {
+	return fmt.Sprintf("%d of %d parts delivered, parts %v failed permanently", e.Sent, e.Total, e.Failed)
+}
+
+// ErrPartsPending is returned when some parts are still waiting for a retry
+type ErrPartsPending struct {
+	Pending int
+	Err     error // Last failure
+}
+
+func (e *ErrPartsPending) Error() string {
+	return fmt.Sprintf("%d parts pending retry: %v", e.Pending, e.Err)
+}
+
+func (e *ErrPartsPending) Unwrap() error {
+	return e.Err
 }
 
 // ErrRepository wraps repository errors
@@ -56,3 +90,54 @@ func (e *ErrWebhook) Error() string {
 func (e *ErrWebhook) Unwrap() error {
 	return e.Err
 }
+
+// ErrDelivery is returned by provider adapters to classify a failed delivery.
+// Permanent failures (invalid number, opted out, bad credentials) will never
+// succeed on retry, transient ones (throttling, provider outage) may.
+type ErrDelivery struct {
+	Permanent bool
+	Code      string // Provider specific error code
+	Err       error
+}
+
+func (e *ErrDelivery) Error() string {
+	class := "transient"
+	if e.Permanent {
+		class = "permanent"
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s delivery failure (code %s): %v", class, e.Code, e.Err)
+	}
+	return fmt.Sprintf("%s delivery failure: %v", class, e.Err)
+}
+
+func (e *ErrDelivery) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanentFailure reports whether err was classified as a permanent delivery failure
+func IsPermanentFailure(err error) bool {
+	var deliveryErr *ErrDelivery
+	return errors.As(err, &deliveryErr) && deliveryErr.Permanent
+}
+
+// ErrThrottled is returned when the provider asked us to slow down (429/503).
+// Throttling is not a delivery failure, the message is requeued as is.
+type ErrThrottled struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ErrThrottled) Error() string {
+	return fmt.Sprintf("provider throttled, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *ErrThrottled) Unwrap() error {
+	return e.Err
+}
+
+// IsThrottled reports whether err is a provider throttling signal
+func IsThrottled(err error) bool {
+	var throttledErr *ErrThrottled
+	return errors.As(err, &throttledErr)
+}
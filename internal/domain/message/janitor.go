@@ -0,0 +1,128 @@
+package message
+
+import (
+	"context"
+	"insider-case/internal/pkg/logger"
+	"sync"
+	"time"
+)
+
+// JanitorTask is a periodic housekeeping job such as pruning old records
+type JanitorTask interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// Janitor runs housekeeping tasks on a fixed interval
+type Janitor struct {
+	tasks    []JanitorTask
+	interval time.Duration
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewJanitor creates a new Janitor, each run of a task is bounded by timeout
+func NewJanitor(interval, timeout time.Duration, tasks ...JanitorTask) *Janitor {
+	return &Janitor{
+		tasks:    tasks,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Start starts running the tasks in the background
+func (j *Janitor) Start() {
+	if j.interval <= 0 || len(j.tasks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		j.runTasks(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runTasks(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the janitor and waits for a running task to finish
+func (j *Janitor) Stop() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	j.wg.Wait()
+}
+
+func (j *Janitor) runTasks(ctx context.Context) {
+	for _, task := range j.tasks {
+		if ctx.Err() != nil {
+			return
+		}
+
+		taskCtx, cancel := context.WithTimeout(ctx, j.timeout)
+		if err := task.Run(taskCtx); err != nil {
+			logger.Error("Janitor task failed", "task", task.Name(), "error", err)
+		}
+		cancel()
+	}
+}
+
+// AttemptPruner deletes delivery attempts older than the retention period
+type AttemptPruner struct {
+	repo      Repository
+	retention time.Duration
+	batchSize int
+}
+
+// NewAttemptPruner creates a new AttemptPruner
+func NewAttemptPruner(repo Repository, retention time.Duration, batchSize int) *AttemptPruner {
+	return &AttemptPruner{
+		repo:      repo,
+		retention: retention,
+		batchSize: batchSize,
+	}
+}
+
+func (p *AttemptPruner) Name() string {
+	return "attempt_retention"
+}
+
+// Run deletes expired attempts in batches to keep locks short
+func (p *AttemptPruner) Run(ctx context.Context) error {
+	if p.retention <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-p.retention)
+	var total int64
+	for {
+		deleted, err := p.repo.DeleteAttemptsBefore(ctx, cutoff, p.batchSize)
+		if err != nil {
+			return &ErrRepository{Operation: "delete expired attempts", Err: err}
+		}
+		total += deleted
+		if deleted < int64(p.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info("Pruned expired delivery attempts", "deleted", total, "cutoff", cutoff)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package message
 
 import (
 	"insider-case/internal/constants"
+	"insider-case/internal/pkg/sms"
 	"time"
 )
 
@@ -12,6 +13,7 @@ const (
 	MessageStatusQueued     MessageStatus = MessageStatus(constants.MessageStatusQueued)     // Kuyruğa alındı, gönderilmeyi bekliyor
 	MessageStatusProcessing MessageStatus = MessageStatus(constants.MessageStatusProcessing) // Şu an gönderiliyor
 	MessageStatusSent       MessageStatus = MessageStatus(constants.MessageStatusSent)       // Provider'a başarıyla iletildi
+	MessageStatusPartial    MessageStatus = MessageStatus(constants.MessageStatusPartial)    // Parçaların bir kısmı iletilemedi
 	MessageStatusDelivered  MessageStatus = MessageStatus(constants.MessageStatusDelivered)
 	MessageStatusFailed     MessageStatus = MessageStatus(constants.MessageStatusFailed)    // Gönderim başarısız
 	MessageStatusCancelled  MessageStatus = MessageStatus(constants.MessageStatusCancelled) // İptal edildi
@@ -20,13 +22,27 @@ const (
 // Message represents a message entity in the domain
 type Message struct {
 	ID        uint          `gorm:"primaryKey" json:"id"`
-	To        string        `gorm:"not null" json:"to"`
+	Channel   string        `gorm:"type:varchar(20);not null;default:'sms'" json:"channel"`
+	To        string        `gorm:"not null" json:"to"`                         // Phone number, email address or device token depending on Channel
+	Subject   string        `gorm:"type:varchar(255)" json:"subject,omitempty"` // Email subject or push title
 	Content   string        `gorm:"not null" json:"content"`
 	Status    MessageStatus `gorm:"type:varchar(20);default:'queued'" json:"status"`
-	MessageID string        `gorm:"type:varchar(255)" json:"message_id,omitempty"`
+	MessageID string        `gorm:"type:varchar(255);index" json:"message_id,omitempty"`
+
+	// SMS encoding, filled in when the message is first processed
+	Encoding     string `gorm:"type:varchar(10)" json:"encoding,omitempty"`
+	SegmentCount int    `gorm:"default:0" json:"segment_count,omitempty"`
+
+	Attachments []*Attachment `gorm:"foreignKey:MessageID" json:"attachments,omitempty"`
 
 	// Retry tracking
-	RetryCount int `gorm:"default:0" json:"retry_count,omitempty"`
+	RetryCount    int        `gorm:"default:0" json:"retry_count,omitempty"`   // Scheduler runs that ended in a retryable failure
+	AttemptCount  int        `gorm:"default:0" json:"attempt_count,omitempty"` // Provider calls counted against the retry budget
+	CallCount     int        `gorm:"default:0" json:"call_count,omitempty"`    // Provider calls made, throttled ones included
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+
+	// Set from the provider's delivery receipt, for split messages once every part is delivered
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
 
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -38,7 +54,16 @@ func (Message) TableName() string {
 	return "messages"
 }
 
-// IsValidContent checks if message content is within character limit
-func (m *Message) IsValidContent(maxLength int) bool {
-	return len(m.Content) > 0 && len(m.Content) <= maxLength
+// Analyze detects the SMS encoding of the content and records its segment count
+func (m *Message) Analyze() sms.Analysis {
+	analysis := sms.Analyze(m.Content)
+	m.Encoding = string(analysis.Encoding)
+	m.SegmentCount = analysis.Segments
+	return analysis
+}
+
+// IsValidContent checks if message content fits within maxSegments SMS segments
+func (m *Message) IsValidContent(maxSegments int) bool {
+	segments := sms.Analyze(m.Content).Segments
+	return segments > 0 && segments <= maxSegments
 }
@@ -0,0 +1,36 @@
+package message
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_Analyze(t *testing.T) {
+	msg := &Message{Content: "Merhaba, bu bir test mesajıdır."}
+	analysis := msg.Analyze()
+
+	assert.Equal(t, "GSM-7-TR", msg.Encoding)
+	assert.Equal(t, 1, msg.SegmentCount)
+	assert.Equal(t, 31, analysis.Characters)
+}
+
+func TestMessage_IsValidContent(t *testing.T) {
+	assert.False(t, (&Message{Content: ""}).IsValidContent(1))
+	assert.True(t, (&Message{Content: strings.Repeat("a", 160)}).IsValidContent(1))
+	assert.False(t, (&Message{Content: strings.Repeat("a", 161)}).IsValidContent(1))
+	// 70 UCS-2 characters fit one segment even though they take 140+ bytes
+	assert.True(t, (&Message{Content: strings.Repeat("ж", 70)}).IsValidContent(1))
+}
+
+func TestSendMessageRequest_Validate(t *testing.T) {
+	req := &SendMessageRequest{To: "+905551111111", Content: strings.Repeat("ş", 300)}
+	err := req.Validate(2)
+
+	var segmentsErr *ErrTooManySegments
+	assert.ErrorAs(t, err, &segmentsErr)
+	assert.Equal(t, "GSM-7-TR", segmentsErr.Encoding)
+	assert.Equal(t, 5, segmentsErr.Segments)
+	assert.NoError(t, req.Validate(5))
+}
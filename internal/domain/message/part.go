@@ -0,0 +1,53 @@
+package message
+
+import (
+	"insider-case/internal/pkg/sms"
+	"time"
+)
+
+// MessagePart is one SMS segment of a message sent as concatenated parts.
+// Each part is delivered and retried on its own, the parent message becomes
+// sent once every part is.
+type MessagePart struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	MessageID    uint          `gorm:"not null;uniqueIndex:idx_message_parts_message_part" json:"message_id"`
+	PartNumber   int           `gorm:"not null;uniqueIndex:idx_message_parts_message_part" json:"part_number"`
+	TotalParts   int           `gorm:"not null" json:"total_parts"`
+	Reference    int           `gorm:"not null" json:"reference"`
+	Content      string        `gorm:"not null" json:"content"`
+	Status       MessageStatus `gorm:"type:varchar(20);default:'queued'" json:"status"`
+	ProviderID   string        `gorm:"type:varchar(255);index" json:"provider_message_id,omitempty"`
+	AttemptCount int           `gorm:"default:0" json:"attempt_count,omitempty"`
+	CallCount    int           `gorm:"default:0" json:"call_count,omitempty"`
+	Error        string        `gorm:"type:text" json:"error,omitempty"`
+	DeliveredAt  *time.Time    `json:"delivered_at,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// TableName specifies the table name for MessagePart
+func (MessagePart) TableName() string {
+	return "message_parts"
+}
+
+// UDH returns the concatenation User Data Header of the part
+func (p *MessagePart) UDH() string {
+	return sms.ConcatUDH(p.Reference, p.TotalParts, p.PartNumber)
+}
+
+// newMessageParts splits the content of msg into parts
+func newMessageParts(msg *Message) []*MessagePart {
+	contents := sms.Split(msg.Content)
+	parts := make([]*MessagePart, len(contents))
+	for i, content := range contents {
+		parts[i] = &MessagePart{
+			MessageID:  msg.ID,
+			PartNumber: i + 1,
+			TotalParts: len(contents),
+			Reference:  int(msg.ID % 256),
+			Content:    content,
+			Status:     MessageStatusQueued,
+		}
+	}
+	return parts
+}
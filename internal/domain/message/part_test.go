@@ -0,0 +1,207 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"insider-case/internal/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+// partsRepo is an in-memory Repository for a single message
+type partsRepo struct {
+	Repository
+	msg      *Message
+	parts    []*MessagePart
+	stored   map[int]MessagePart // Last stored copy of each part by number
+	attempts []*MessageAttempt
+}
+
+func (r *partsRepo) GetUnsentMessages(ctx context.Context, limit int) ([]*Message, error) {
+	if r.msg.Status != MessageStatusQueued {
+		return nil, nil
+	}
+	r.msg.Status = MessageStatusProcessing
+	copied := *r.msg
+	return []*Message{&copied}, nil
+}
+
+func (r *partsRepo) UpdateMessageStatus(ctx context.Context, id uint, status MessageStatus, messageID string) error {
+	r.msg.Status, r.msg.MessageID = status, messageID
+	return nil
+}
+
+func (r *partsRepo) UpdateMessageStatusOnly(ctx context.Context, id uint, status MessageStatus) error {
+	r.msg.Status = status
+	return nil
+}
+
+func (r *partsRepo) UpdateMessageStatusAndRetry(ctx context.Context, id uint, status MessageStatus, retryCount int) error {
+	r.msg.Status, r.msg.RetryCount = status, retryCount
+	return nil
+}
+
+func (r *partsRepo) UpdateMessageRetry(ctx context.Context, id uint, retryCount int) error {
+	r.msg.Status, r.msg.RetryCount = MessageStatusQueued, retryCount
+	return nil
+}
+
+func (r *partsRepo) UpdateMessageEncoding(ctx context.Context, id uint, encoding string, segmentCount int) error {
+	return nil
+}
+
+func (r *partsRepo) GetMessageParts(ctx context.Context, messageID uint) ([]*MessagePart, error) {
+	return r.parts, nil
+}
+
+func (r *partsRepo) CreateMessageParts(ctx context.Context, parts []*MessagePart) error {
+	for i, part := range parts {
+		part.ID = uint(i + 1)
+	}
+	r.parts = parts
+	return nil
+}
+
+func (r *partsRepo) UpdateMessagePart(ctx context.Context, part *MessagePart) error {
+	if r.stored == nil {
+		r.stored = make(map[int]MessagePart)
+	}
+	r.stored[part.PartNumber] = *part
+	return nil
+}
+
+func (r *partsRepo) RecordAttempt(ctx context.Context, attempt *MessageAttempt) error {
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+// partsWebhook answers each part with the error configured for its number
+type partsWebhook struct {
+	errs     map[int]error
+	requests []*WebhookRequest
+}
+
+func (w *partsWebhook) SendMessage(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
+	budget := AttemptBudgetFromContext(ctx)
+	attempt := budget.Start()
+	w.requests = append(w.requests, req)
+
+	err := w.errs[req.Part.Number]
+	attempt.Err = err
+	_ = budget.Record(ctx, attempt)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookResponse{Message: "Accepted", MessageID: "part-" + strconv.Itoa(req.Part.Number)}, nil
+}
+
+func newPartsService(repo *partsRepo, webhook *partsWebhook) *Service {
+	return NewService(repo, nil, webhook, 1, 6, true, NewRetryPolicy(2, 1), time.Second)
+}
+
+func longMessage() *Message {
+	return &Message{ID: 300, To: "+905551111111", Content: strings.Repeat("a", 400), Status: MessageStatusQueued}
+}
+
+func TestService_SendsLongMessageAsParts(t *testing.T) {
+	repo := &partsRepo{msg: longMessage()}
+	webhook := &partsWebhook{}
+
+	require.NoError(t, newPartsService(repo, webhook).SendPendingMessages(context.Background()))
+
+	require.Len(t, webhook.requests, 3)
+	for i, req := range webhook.requests {
+		assert.Equal(t, i+1, req.Part.Number)
+		assert.Equal(t, 3, req.Part.Total)
+		assert.Equal(t, 44, req.Part.Reference)
+	}
+	assert.Equal(t, "0500032C0301", webhook.requests[0].Part.UDH)
+	assert.Equal(t, repo.msg.Content, webhook.requests[0].Content+webhook.requests[1].Content+webhook.requests[2].Content)
+	assert.Equal(t, MessageStatusSent, repo.msg.Status)
+	assert.Equal(t, "part-1", repo.msg.MessageID)
+	assert.Len(t, repo.attempts, 3)
+	assert.Equal(t, 2, repo.attempts[1].PartNumber)
+}
+
+func TestService_RetriesOnlyPendingParts(t *testing.T) {
+	repo := &partsRepo{msg: longMessage()}
+	webhook := &partsWebhook{errs: map[int]error{2: &ErrDelivery{Err: errors.New("timeout")}}}
+	service := newPartsService(repo, webhook)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, MessageStatusQueued, repo.msg.Status)
+	assert.Equal(t, MessageStatusQueued, repo.parts[1].Status)
+
+	webhook.errs = nil
+	webhook.requests = nil
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+
+	require.Len(t, webhook.requests, 1)
+	assert.Equal(t, 2, webhook.requests[0].Part.Number)
+	assert.Equal(t, MessageStatusSent, repo.msg.Status)
+}
+
+func TestService_ReportsPartialDelivery(t *testing.T) {
+	repo := &partsRepo{msg: longMessage()}
+	webhook := &partsWebhook{errs: map[int]error{3: &ErrDelivery{Permanent: true, Err: errors.New("rejected")}}}
+
+	require.NoError(t, newPartsService(repo, webhook).SendPendingMessages(context.Background()))
+
+	assert.Equal(t, MessageStatusPartial, repo.msg.Status)
+	assert.Equal(t, MessageStatusSent, repo.parts[0].Status)
+	assert.Equal(t, MessageStatusFailed, repo.parts[2].Status)
+	assert.Contains(t, repo.parts[2].Error, "rejected")
+}
+
+func TestService_FailsWhenEveryPartFails(t *testing.T) {
+	repo := &partsRepo{msg: longMessage()}
+	permanent := &ErrDelivery{Permanent: true, Err: errors.New("invalid number")}
+	webhook := &partsWebhook{errs: map[int]error{1: permanent, 2: permanent, 3: permanent}}
+
+	require.NoError(t, newPartsService(repo, webhook).SendPendingMessages(context.Background()))
+	assert.Equal(t, MessageStatusFailed, repo.msg.Status)
+}
+
+// throttledWebhook fails part 2 once, then is throttled on the retry
+type throttledWebhook struct {
+	partsWebhook
+}
+
+func (w *throttledWebhook) SendMessage(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
+	if req.Part.Number != 2 {
+		return w.partsWebhook.SendMessage(ctx, req)
+	}
+	budget := AttemptBudgetFromContext(ctx)
+	failed := budget.Start()
+	failed.Err = &ErrDelivery{Err: errors.New("timeout")}
+	_ = budget.Record(ctx, failed)
+
+	throttled := &ErrThrottled{RetryAfter: time.Second, Err: errors.New("429")}
+	attempt := budget.Start()
+	attempt.Err, attempt.Throttled = throttled, true
+	_ = budget.Record(ctx, attempt)
+	return nil, throttled
+}
+
+func TestService_StoresPartAttemptsWhenThrottled(t *testing.T) {
+	repo := &partsRepo{msg: longMessage()}
+	service := NewService(repo, nil, &throttledWebhook{}, 1, 6, true, NewRetryPolicy(5, 3), time.Second)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+
+	stored, ok := repo.stored[2]
+	require.True(t, ok, "throttled part is stored")
+	assert.Equal(t, MessageStatusQueued, stored.Status)
+	assert.Equal(t, 1, stored.AttemptCount, "the failed attempt before the throttle counts")
+	assert.Equal(t, MessageStatusQueued, repo.msg.Status)
+}
@@ -0,0 +1,32 @@
+package message
+
+import "context"
+
+// Queue hands queued messages to the service. The database stays the system
+// of record: a claimed message is in processing status whichever queue
+// claimed it, and its outcome is stored in the database before Ack.
+type Queue interface {
+	// Claim returns up to limit messages to deliver
+	Claim(ctx context.Context, limit int) ([]*Message, error)
+	// Ack releases a claimed message once its outcome is stored
+	Ack(ctx context.Context, msg *Message) error
+}
+
+// pollingQueue claims messages by polling the database
+type pollingQueue struct {
+	repo Repository
+}
+
+// NewPollingQueue creates a Queue that claims queued rows from the database
+func NewPollingQueue(repo Repository) Queue {
+	return &pollingQueue{repo: repo}
+}
+
+func (q *pollingQueue) Claim(ctx context.Context, limit int) ([]*Message, error) {
+	return q.repo.GetUnsentMessages(ctx, limit)
+}
+
+// Ack is a no-op, the stored status already releases the message
+func (q *pollingQueue) Ack(ctx context.Context, msg *Message) error {
+	return nil
+}
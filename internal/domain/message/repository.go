@@ -7,31 +7,122 @@ import (
 
 // Repository defines the interface for message persistence operations
 type Repository interface {
-	GetUnsentMessages(ctx context.Context, limit int, maxRetryAttempts int) ([]*Message, error)
+	GetUnsentMessages(ctx context.Context, limit int) ([]*Message, error)
 	UpdateMessageStatus(ctx context.Context, id uint, status MessageStatus, messageID string) error
 	UpdateMessageStatusOnly(ctx context.Context, id uint, status MessageStatus) error
 	UpdateMessageStatusAndRetry(ctx context.Context, id uint, status MessageStatus, retryCount int) error
 	UpdateMessageRetry(ctx context.Context, id uint, retryCount int) error
+	UpdateMessageEncoding(ctx context.Context, id uint, encoding string, segmentCount int) error
 	GetSentMessages(ctx context.Context, limit, offset int) ([]*Message, error)
 	CountSentMessages(ctx context.Context) (int64, error)
+	GetMessageByID(ctx context.Context, id uint) (*Message, error)
+	GetMessagesByIDs(ctx context.Context, ids []uint) ([]*Message, error)
+	GetMessageByProviderID(ctx context.Context, providerID string) (*Message, error)
+	MarkMessageDelivered(ctx context.Context, id uint, deliveredAt time.Time) error
+
+	// Concatenated message parts
+	GetMessageParts(ctx context.Context, messageID uint) ([]*MessagePart, error)
+	CreateMessageParts(ctx context.Context, parts []*MessagePart) error
+	UpdateMessagePart(ctx context.Context, part *MessagePart) error
+	MarkPartDelivered(ctx context.Context, id uint, deliveredAt time.Time) error
+
+	// Delivery attempts
+	RecordAttempt(ctx context.Context, attempt *MessageAttempt) error
+	GetAttempts(ctx context.Context, messageID uint) ([]*MessageAttempt, error)
+	DeleteAttemptsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
 }
 
 // CacheRepository defines the interface for cache operations
 type CacheRepository interface {
-	SetMessageID(ctx context.Context, messageID string, sentAt time.Time) error
-	GetMessageID(ctx context.Context, messageID string) (*time.Time, error)
+	SetMessageID(ctx context.Context, id uint, messageID string, sentAt time.Time) error
+	GetMessageID(ctx context.Context, messageID string) (*CachedMessageID, error)
+
+	// BeginDelivery marks a provider call for a message (part 0) or one of its
+	// parts as in flight. When a marker already exists it is returned instead.
+	BeginDelivery(ctx context.Context, id uint, part int) (*DeliveryMarker, error)
+	// CompleteDelivery records that the provider accepted the message
+	CompleteDelivery(ctx context.Context, id uint, part int, providerID string) error
+	// ClearDelivery removes the marker after the provider rejected the message
+	ClearDelivery(ctx context.Context, id uint, part int) error
+}
+
+// ListCache defines the interface for the read-through cache of sent message
+// pages and their count. Get methods return nil on a miss.
+type ListCache interface {
+	GetSentPage(ctx context.Context, limit, offset int) (*CachedPage, error)
+	SetSentPage(ctx context.Context, limit, offset int, messages []*Message) error
+	GetSentCount(ctx context.Context) (*CachedCount, error)
+	SetSentCount(ctx context.Context, total int64) error
+
+	// InvalidateSent drops the count and every page a message created at
+	// createdAt shows up on once it is sent
+	InvalidateSent(ctx context.Context, createdAt time.Time) error
+	// Flush drops every cached page and count
+	Flush(ctx context.Context) error
+}
+
+// CachedPage is a cached page of sent messages
+type CachedPage struct {
+	Messages []*Message
+	CachedAt time.Time
+}
+
+// CachedCount is the cached number of sent messages
+type CachedCount struct {
+	Total    int64
+	CachedAt time.Time
+}
+
+// CachedMessageID maps a provider message ID to our message
+type CachedMessageID struct {
+	ID        uint // Our message ID, zero for entries cached before it was stored
+	MessageID string
+	SentAt    time.Time
+}
+
+// DeliveryState is the state of a delivery marker
+type DeliveryState string
+
+const (
+	DeliveryInFlight DeliveryState = "in_flight"
+	DeliverySent     DeliveryState = "sent"
+)
+
+// DeliveryMarker tracks a provider call outside the database, so a message
+// whose status update was lost is reconciled instead of sent twice
+type DeliveryMarker struct {
+	State      DeliveryState `json:"state"`
+	ProviderID string        `json:"provider_id,omitempty"`
+	UpdatedAt  time.Time     `json:"updated_at"`
 }
 
 // WebhookRequest represents the request payload for webhook
 type WebhookRequest struct {
-	To      string `json:"to"`
-	Content string `json:"content"`
+	To      string              `json:"to"`
+	Subject string              `json:"subject,omitempty"` // Email subject or push title
+	Content string              `json:"content"`
+	Part    *WebhookRequestPart `json:"part,omitempty"` // Set when sent as one part of a long message
+	Media   []WebhookMedia      `json:"media,omitempty"`
+}
+
+// WebhookMedia is an attachment the provider fetches by URL
+type WebhookMedia struct {
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+}
+
+// WebhookRequestPart carries concatenation metadata of a message part
+type WebhookRequestPart struct {
+	Reference int    `json:"reference"` // 8-bit reference shared by all parts
+	Number    int    `json:"number"`    // 1-based sequence number
+	Total     int    `json:"total"`
+	UDH       string `json:"udh"` // Hex encoded concatenation User Data Header
 }
 
 // WebhookResponse represents the response from webhook
 type WebhookResponse struct {
-	Message   string `json:"message"`
-	MessageID string `json:"messageId"`
+	Message   string `json:"message"`   // Provider status text
+	MessageID string `json:"messageId"` // Provider message ID
 }
 
 // WebhookClient defines the interface for webhook operations
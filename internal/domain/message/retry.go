@@ -0,0 +1,142 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAttemptBudgetExhausted is returned when no provider call is left in the attempt budget
+var ErrAttemptBudgetExhausted = errors.New("delivery attempt budget exhausted")
+
+// RetryPolicy owns the delivery attempt budget of a message.
+//
+// Every HTTP call to the provider is one attempt, whether the transport or the
+// service decided to make it. The transport retries transient failures within a
+// scheduler run, up to AttemptsPerRun calls. The service requeues the message
+// for a later run while the total budget (MaxAttempts) is not spent, and marks
+// it failed once it is, or as soon as a failure is classified as permanent.
+// Throttled calls are recorded but do not consume the total budget.
+type RetryPolicy struct {
+	MaxAttempts    int // Total provider calls allowed per message
+	AttemptsPerRun int // Provider calls allowed per message within one scheduler run
+}
+
+// NewRetryPolicy creates a new RetryPolicy
+func NewRetryPolicy(maxAttempts, attemptsPerRun int) *RetryPolicy {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if attemptsPerRun < 1 {
+		attemptsPerRun = 1
+	}
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		AttemptsPerRun: attemptsPerRun,
+	}
+}
+
+// Remaining returns how many attempts of the total budget are left after used
+func (p *RetryPolicy) Remaining(used int) int {
+	if remaining := p.MaxAttempts - used; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Exhausted reports whether used attempts spent the total budget
+func (p *RetryPolicy) Exhausted(used int) bool {
+	return p.Remaining(used) == 0
+}
+
+// NewBudget returns the attempt budget for one processing run of msg.
+// record is called after every provider call, typically to persist the attempt.
+func (p *RetryPolicy) NewBudget(msg *Message, record func(ctx context.Context, attempt *Attempt) error) *AttemptBudget {
+	return p.newBudget(msg.AttemptCount, msg.CallCount, record)
+}
+
+// newBudget numbers attempts after all calls made so far, so throttled calls
+// that did not use the budget still keep their attempt numbers.
+func (p *RetryPolicy) newBudget(used, calls int, record func(ctx context.Context, attempt *Attempt) error) *AttemptBudget {
+	return &AttemptBudget{
+		remaining: p.Remaining(used),
+		perRun:    p.AttemptsPerRun,
+		offset:    calls,
+		record:    record,
+	}
+}
+
+// Attempt describes a single provider call
+type Attempt struct {
+	Number       int // 1-based attempt number across all runs of the message
+	Provider     string
+	RequestedAt  time.Time
+	Latency      time.Duration
+	StatusCode   int    // 0 when no response was received
+	ResponseBody string // Truncated response body
+	Throttled    bool   // Provider asked us to slow down, does not consume the budget
+	Err          error
+}
+
+// AttemptBudget tracks the provider calls made for a message during one run.
+// It is passed to the transport through the context.
+type AttemptBudget struct {
+	remaining int // Budget-consuming calls left for the message
+	perRun    int // Calls allowed in this run, throttled ones included
+	offset    int // Calls made in earlier runs, throttled ones included
+	record    func(ctx context.Context, attempt *Attempt) error
+
+	calls   int // Calls made in this run
+	counted int // Budget-consuming calls made in this run
+}
+
+// Allow reports whether another provider call may be made
+func (b *AttemptBudget) Allow() bool {
+	return b.calls < b.perRun && b.counted < b.remaining
+}
+
+// Start returns the attempt descriptor for the next provider call
+func (b *AttemptBudget) Start() *Attempt {
+	return &Attempt{
+		Number:      b.offset + b.calls + 1,
+		RequestedAt: time.Now(),
+	}
+}
+
+// Record registers a finished provider call and persists it
+func (b *AttemptBudget) Record(ctx context.Context, attempt *Attempt) error {
+	b.calls++
+	if !attempt.Throttled {
+		b.counted++
+	}
+	if b.record == nil {
+		return nil
+	}
+	return b.record(ctx, attempt)
+}
+
+// Calls returns the provider calls made in this run
+func (b *AttemptBudget) Calls() int {
+	return b.calls
+}
+
+// Counted returns the budget-consuming calls made in this run
+func (b *AttemptBudget) Counted() int {
+	return b.counted
+}
+
+type attemptBudgetKey struct{}
+
+// WithAttemptBudget returns a context carrying the attempt budget
+func WithAttemptBudget(ctx context.Context, budget *AttemptBudget) context.Context {
+	return context.WithValue(ctx, attemptBudgetKey{}, budget)
+}
+
+// AttemptBudgetFromContext returns the attempt budget carried by ctx.
+// Without one, a single attempt is allowed.
+func AttemptBudgetFromContext(ctx context.Context) *AttemptBudget {
+	if budget, ok := ctx.Value(attemptBudgetKey{}).(*AttemptBudget); ok && budget != nil {
+		return budget
+	}
+	return &AttemptBudget{remaining: 1, perRun: 1}
+}
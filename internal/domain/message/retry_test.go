@@ -0,0 +1,80 @@
+package message
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_BudgetPerRun(t *testing.T) {
+	policy := NewRetryPolicy(4, 2)
+
+	budget := policy.NewBudget(&Message{AttemptCount: 0}, nil)
+	assert.True(t, budget.Allow())
+	assert.Equal(t, 1, budget.Start().Number)
+	_ = budget.Record(context.Background(), &Attempt{})
+	assert.True(t, budget.Allow())
+	_ = budget.Record(context.Background(), &Attempt{})
+	assert.False(t, budget.Allow(), "run allowance is spent")
+	assert.Equal(t, 2, budget.Counted())
+}
+
+func TestRetryPolicy_BudgetRemaining(t *testing.T) {
+	policy := NewRetryPolicy(4, 2)
+
+	budget := policy.NewBudget(&Message{AttemptCount: 3, CallCount: 3}, nil)
+	assert.Equal(t, 4, budget.Start().Number)
+	_ = budget.Record(context.Background(), &Attempt{})
+	assert.False(t, budget.Allow(), "total budget is spent")
+	assert.True(t, policy.Exhausted(3+budget.Counted()))
+
+	assert.False(t, policy.NewBudget(&Message{AttemptCount: 4}, nil).Allow())
+}
+
+func TestRetryPolicy_ThrottledAttemptsAreFree(t *testing.T) {
+	policy := NewRetryPolicy(1, 3)
+
+	budget := policy.NewBudget(&Message{}, nil)
+	_ = budget.Record(context.Background(), &Attempt{Throttled: true})
+	assert.True(t, budget.Allow())
+	assert.Equal(t, 0, budget.Counted())
+	assert.Equal(t, 1, budget.Calls())
+}
+
+func TestRetryPolicy_NumbersAttemptsAfterThrottledCalls(t *testing.T) {
+	policy := NewRetryPolicy(3, 1)
+	msg := &Message{}
+
+	first := policy.NewBudget(msg, nil)
+	throttled := first.Start()
+	throttled.Throttled = true
+	_ = first.Record(context.Background(), throttled)
+	msg.AttemptCount += first.Counted()
+	msg.CallCount += first.Calls()
+
+	retry := policy.NewBudget(msg, nil)
+	assert.True(t, retry.Allow())
+	assert.Equal(t, 1, throttled.Number)
+	assert.Equal(t, 2, retry.Start().Number)
+}
+
+func TestRetryPolicy_RecordsAttempts(t *testing.T) {
+	var recorded []int
+	budget := NewRetryPolicy(3, 3).NewBudget(&Message{AttemptCount: 1, CallCount: 1}, func(ctx context.Context, attempt *Attempt) error {
+		recorded = append(recorded, attempt.Number)
+		return nil
+	})
+
+	for budget.Allow() {
+		_ = budget.Record(context.Background(), budget.Start())
+	}
+	assert.Equal(t, []int{2, 3}, recorded)
+}
+
+func TestAttemptBudgetFromContext_DefaultsToSingleAttempt(t *testing.T) {
+	budget := AttemptBudgetFromContext(context.Background())
+	assert.True(t, budget.Allow())
+	_ = budget.Record(context.Background(), &Attempt{})
+	assert.False(t, budget.Allow())
+}
@@ -2,45 +2,101 @@ package message
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"insider-case/internal/constants"
 	"insider-case/internal/pkg/logger"
+	"sync"
 	"time"
 )
 
 // Service handles message-related business logic
 type Service struct {
 	repo             Repository
+	cacheMu          sync.RWMutex
 	cacheRepo        CacheRepository
-	webhookClient    WebhookClient
+	listCache        ListCache
+	queue            Queue
+	channels         *ChannelRegistry
+	attachments      *AttachmentService
 	messagesPerBatch int
-	maxMessageLength int
-	maxRetryAttempts int
+	splitLong        bool
+	retryPolicy      *RetryPolicy
 	retryBaseDelay   time.Duration
 }
 
-// NewService creates a new MessageService
+// NewService creates a new MessageService. webhookClient delivers the SMS
+// channel, other channels are added with RegisterChannel.
 func NewService(
 	repo Repository,
 	cacheRepo CacheRepository,
 	webhookClient WebhookClient,
 	messagesPerBatch int,
-	maxMessageLength int,
-	maxRetryAttempts int,
+	maxSegments int,
+	splitLong bool,
+	retryPolicy *RetryPolicy,
 	retryBaseDelay time.Duration,
 ) *Service {
+	channels := NewChannelRegistry()
+	channels.Register(constants.ChannelSMS, webhookClient, &SMSValidator{MaxSegments: maxSegments})
+
 	return &Service{
 		repo:             repo,
 		cacheRepo:        cacheRepo,
-		webhookClient:    webhookClient,
+		queue:            NewPollingQueue(repo),
+		channels:         channels,
 		messagesPerBatch: messagesPerBatch,
-		maxMessageLength: maxMessageLength,
-		maxRetryAttempts: maxRetryAttempts,
+		splitLong:        splitLong,
+		retryPolicy:      retryPolicy,
 		retryBaseDelay:   retryBaseDelay,
 	}
 }
 
+// RegisterChannel adds a delivery channel with its sender and validation rules
+func (s *Service) RegisterChannel(name string, sender WebhookClient, validator ChannelValidator) {
+	s.channels.Register(name, sender, validator)
+}
+
+// SetCache swaps the cache in or out while running, nil disables caching
+func (s *Service) SetCache(cacheRepo CacheRepository) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.cacheRepo = cacheRepo
+}
+
+func (s *Service) cache() CacheRepository {
+	s.cacheMu.RLock()
+	defer s.cacheMu.RUnlock()
+	return s.cacheRepo
+}
+
+// SetListCache swaps the sent message list cache in or out while running,
+// nil disables it
+func (s *Service) SetListCache(listCache ListCache) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.listCache = listCache
+}
+
+func (s *Service) lists() ListCache {
+	s.cacheMu.RLock()
+	defer s.cacheMu.RUnlock()
+	return s.listCache
+}
+
+// UseQueue replaces the default database polling queue
+func (s *Service) UseQueue(queue Queue) {
+	s.queue = queue
+}
+
+// EnableAttachments includes message attachments in provider requests
+func (s *Service) EnableAttachments(attachments *AttachmentService) {
+	s.attachments = attachments
+}
+
 // SendPendingMessages processes and sends queued messages
 func (s *Service) SendPendingMessages(ctx context.Context) error {
-	messages, err := s.repo.GetUnsentMessages(ctx, s.messagesPerBatch, s.maxRetryAttempts)
+	messages, err := s.queue.Claim(ctx, s.messagesPerBatch)
 	if err != nil {
 		logger.Error("Failed to get unsent messages", "error", err)
 		return &ErrRepository{Operation: "get unsent messages", Err: err}
@@ -58,17 +114,20 @@ func (s *Service) SendPendingMessages(ctx context.Context) error {
 					"error", err,
 				)
 			}
+			s.ackMessage(ctx, msg)
 			continue
 		}
 
-		if err := s.processMessage(ctx, msg); err != nil {
-			if err := s.handleFailedMessage(ctx, msg, err); err != nil {
+		budget := s.retryPolicy.NewBudget(msg, s.recordAttempt(msg))
+		if err := s.processMessage(WithAttemptBudget(ctx, budget), msg); err != nil {
+			if err := s.handleFailedMessage(ctx, msg, budget, err); err != nil {
 				logger.Error("Failed to handle failed message",
 					"message_id", msg.ID,
 					"error", err,
 				)
 			}
 		}
+		s.ackMessage(ctx, msg)
 	}
 
 	return nil
@@ -76,56 +135,361 @@ func (s *Service) SendPendingMessages(ctx context.Context) error {
 
 // processMessage processes a single message
 func (s *Service) processMessage(ctx context.Context, msg *Message) error {
-	if !msg.IsValidContent(s.maxMessageLength) {
-		return &ErrContentLengthExceeded{
-			Length:    len(msg.Content),
-			MaxLength: s.maxMessageLength,
+	channel, err := s.channels.Lookup(msg.Channel)
+	if err != nil {
+		return &ErrDelivery{Permanent: true, Code: "unsupported_channel", Err: err}
+	}
+
+	validationErr := channel.Validator.Validate(msg)
+	isSMS := channel.Name == constants.ChannelSMS
+	if isSMS {
+		if err := s.repo.UpdateMessageEncoding(ctx, msg.ID, msg.Encoding, msg.SegmentCount); err != nil {
+			logger.Warn("Failed to store message encoding",
+				"message_id", msg.ID,
+				"error", err,
+			)
+		}
+	}
+	if validationErr != nil {
+		return &ErrDelivery{Permanent: true, Code: "invalid_content", Err: validationErr}
+	}
+
+	var media []WebhookMedia
+	if s.attachments != nil {
+		if media, err = s.attachments.Media(ctx, msg.ID); err != nil {
+			return err
 		}
 	}
 
+	if isSMS && s.splitLong && msg.SegmentCount > 1 {
+		return s.processParts(ctx, channel.Sender, msg, media)
+	}
+
 	webhookReq := &WebhookRequest{
 		To:      msg.To,
+		Subject: msg.Subject,
 		Content: msg.Content,
+		Media:   media,
 	}
 
-	resp, err := s.webhookClient.SendMessage(ctx, webhookReq)
+	providerID, err := s.beginDelivery(ctx, msg.ID, 0)
 	if err != nil {
-		return &ErrWebhook{Err: err}
+		return err
+	}
+	if providerID == "" {
+		resp, err := channel.Sender.SendMessage(ctx, webhookReq)
+		if err != nil {
+			s.clearDelivery(ctx, msg.ID, 0)
+			return &ErrWebhook{Err: err}
+		}
+		providerID = resp.MessageID
+		s.completeDelivery(ctx, msg.ID, 0, providerID)
 	}
 
-	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, MessageStatusSent, resp.MessageID); err != nil {
+	if err := s.repo.UpdateMessageStatus(ctx, msg.ID, MessageStatusSent, providerID); err != nil {
 		return &ErrRepository{Operation: "update message status", Err: err}
 	}
+	s.invalidateSent(ctx, msg)
 
-	if s.cacheRepo != nil {
-		sentAt := time.Now()
-		if err := s.cacheRepo.SetMessageID(ctx, resp.MessageID, sentAt); err != nil {
-			logger.Warn("Failed to cache messageId",
-				"message_id", resp.MessageID,
-				"error", err,
-			)
+	s.cacheMessageID(ctx, msg.ID, providerID)
+	return nil
+}
+
+// processParts sends a long message as concatenated parts. Each part has its
+// own attempt budget; parts already sent in an earlier run are skipped.
+// Media is sent with the first part only.
+func (s *Service) processParts(ctx context.Context, sender WebhookClient, msg *Message, media []WebhookMedia) error {
+	parts, err := s.repo.GetMessageParts(ctx, msg.ID)
+	if err != nil {
+		return &ErrRepository{Operation: "get message parts", Err: err}
+	}
+	if len(parts) == 0 {
+		parts = newMessageParts(msg)
+		if err := s.repo.CreateMessageParts(ctx, parts); err != nil {
+			return &ErrRepository{Operation: "create message parts", Err: err}
+		}
+	}
+
+	var pendingErr error
+	for _, part := range parts {
+		if part.Status != MessageStatusQueued {
+			continue
+		}
+
+		var partMedia []WebhookMedia
+		if part.PartNumber == 1 {
+			partMedia = media
+		}
+		if err := s.sendPart(ctx, sender, msg, part, partMedia); err != nil {
+			if IsThrottled(err) {
+				return err
+			}
+			pendingErr = err
+		}
+	}
+
+	outcome := &ErrPartialDelivery{Total: len(parts)}
+	pending := 0
+	for _, part := range parts {
+		switch part.Status {
+		case MessageStatusSent:
+			outcome.Sent++
+		case MessageStatusFailed:
+			outcome.Failed = append(outcome.Failed, part.PartNumber)
+		default:
+			pending++
+		}
+	}
+
+	switch {
+	case pending > 0:
+		return &ErrPartsPending{Pending: pending, Err: pendingErr}
+	case outcome.Sent == outcome.Total:
+		if err := s.repo.UpdateMessageStatus(ctx, msg.ID, MessageStatusSent, parts[0].ProviderID); err != nil {
+			return &ErrRepository{Operation: "update message status", Err: err}
+		}
+		s.invalidateSent(ctx, msg)
+		return nil
+	case outcome.Sent == 0:
+		return &ErrDelivery{Permanent: true, Code: "all_parts_failed", Err: outcome}
+	default:
+		logger.Error("Message partially delivered",
+			"message_id", msg.ID,
+			"sent_parts", outcome.Sent,
+			"total_parts", outcome.Total,
+			"failed_parts", outcome.Failed,
+		)
+		if err := s.repo.UpdateMessageStatusOnly(ctx, msg.ID, MessageStatusPartial); err != nil {
+			return &ErrRepository{Operation: "update message status", Err: err}
+		}
+		return nil
+	}
+}
+
+// sendPart sends one part and stores its outcome. Parts that fail permanently
+// or exhaust their attempt budget are marked failed, others stay queued.
+func (s *Service) sendPart(ctx context.Context, sender WebhookClient, msg *Message, part *MessagePart, media []WebhookMedia) error {
+	providerID, err := s.beginDelivery(ctx, msg.ID, part.PartNumber)
+	if err != nil {
+		return err
+	}
+	if providerID == "" {
+		budget := s.retryPolicy.newBudget(part.AttemptCount, part.CallCount, s.recordPartAttempt(msg, part))
+		var resp *WebhookResponse
+		resp, err = sender.SendMessage(WithAttemptBudget(ctx, budget), &WebhookRequest{
+			To:      msg.To,
+			Content: part.Content,
+			Part: &WebhookRequestPart{
+				Reference: part.Reference,
+				Number:    part.PartNumber,
+				Total:     part.TotalParts,
+				UDH:       part.UDH(),
+			},
+			Media: media,
+		})
+		part.AttemptCount += budget.Counted()
+		part.CallCount += budget.Calls()
+		if err != nil {
+			s.clearDelivery(ctx, msg.ID, part.PartNumber)
+		} else {
+			providerID = resp.MessageID
+			s.completeDelivery(ctx, msg.ID, part.PartNumber, providerID)
 		}
 	}
 
+	switch {
+	case err == nil:
+		part.Status = MessageStatusSent
+		part.ProviderID = providerID
+		part.Error = ""
+		s.cacheMessageID(ctx, msg.ID, providerID)
+	case IsThrottled(err):
+		// Stays queued, attempts made before the throttle still count
+		part.Error = err.Error()
+	case IsPermanentFailure(err) || s.retryPolicy.Exhausted(part.AttemptCount):
+		part.Status = MessageStatusFailed
+		part.Error = err.Error()
+	default:
+		part.Error = err.Error()
+	}
+
+	if updateErr := s.repo.UpdateMessagePart(ctx, part); updateErr != nil {
+		logger.Error("Failed to update message part",
+			"message_id", msg.ID,
+			"part", part.PartNumber,
+			"error", updateErr,
+		)
+	}
+
+	if IsThrottled(err) {
+		return err
+	}
+	if err != nil {
+		return &ErrWebhook{Err: fmt.Errorf("part %d/%d: %w", part.PartNumber, part.TotalParts, err)}
+	}
 	return nil
 }
 
-// handleFailedMessage handles retry logic for failed messages
-func (s *Service) handleFailedMessage(ctx context.Context, msg *Message, err error) error {
+// ackMessage releases a message from the queue after its outcome is stored
+func (s *Service) ackMessage(ctx context.Context, msg *Message) {
+	if err := s.queue.Ack(ctx, msg); err != nil {
+		logger.Warn("Failed to acknowledge queued message",
+			"message_id", msg.ID,
+			"error", err,
+		)
+	}
+}
+
+// beginDelivery consults the delivery marker of a message or part before the
+// provider is called. It returns the provider message ID when an earlier run
+// was already accepted, and ErrDeliveryInFlight while an earlier call has an
+// unknown outcome. Without a cache, or when it fails, the message is sent.
+func (s *Service) beginDelivery(ctx context.Context, id uint, part int) (string, error) {
+	cacheRepo := s.cache()
+	if cacheRepo == nil {
+		return "", nil
+	}
+
+	marker, err := cacheRepo.BeginDelivery(ctx, id, part)
+	if err != nil {
+		logger.Warn("Failed to mark delivery in flight, sending without duplicate guard",
+			"message_id", id,
+			"part", part,
+			"error", err,
+		)
+		return "", nil
+	}
+	if marker == nil {
+		return "", nil
+	}
+
+	switch marker.State {
+	case DeliverySent:
+		logger.Warn("Message was already accepted by the provider, reconciling status",
+			"message_id", id,
+			"part", part,
+			"provider_id", marker.ProviderID,
+		)
+		return marker.ProviderID, nil
+	default:
+		logger.Warn("Earlier delivery still in flight, not sending again",
+			"message_id", id,
+			"part", part,
+			"since", marker.UpdatedAt,
+		)
+		return "", ErrDeliveryInFlight
+	}
+}
+
+func (s *Service) completeDelivery(ctx context.Context, id uint, part int, providerID string) {
+	cacheRepo := s.cache()
+	if cacheRepo == nil {
+		return
+	}
+	if err := cacheRepo.CompleteDelivery(ctx, id, part, providerID); err != nil {
+		logger.Warn("Failed to mark delivery sent",
+			"message_id", id,
+			"part", part,
+			"error", err,
+		)
+	}
+}
+
+func (s *Service) clearDelivery(ctx context.Context, id uint, part int) {
+	cacheRepo := s.cache()
+	if cacheRepo == nil {
+		return
+	}
+	if err := cacheRepo.ClearDelivery(ctx, id, part); err != nil {
+		logger.Warn("Failed to clear delivery marker",
+			"message_id", id,
+			"part", part,
+			"error", err,
+		)
+	}
+}
+
+func (s *Service) cacheMessageID(ctx context.Context, id uint, messageID string) {
+	cacheRepo := s.cache()
+	if cacheRepo == nil {
+		return
+	}
+	if err := cacheRepo.SetMessageID(ctx, id, messageID, time.Now()); err != nil {
+		logger.Warn("Failed to cache messageId",
+			"message_id", messageID,
+			"error", err,
+		)
+	}
+}
+
+// invalidateSent drops the cached lists a newly sent message shows up on
+func (s *Service) invalidateSent(ctx context.Context, msg *Message) {
+	listCache := s.lists()
+	if listCache == nil {
+		return
+	}
+	if err := listCache.InvalidateSent(ctx, msg.CreatedAt); err != nil {
+		logger.Warn("Failed to invalidate sent message lists",
+			"message_id", msg.ID,
+			"error", err,
+		)
+	}
+}
+
+// recordAttempt persists every provider call so the attempt budget survives restarts
+func (s *Service) recordAttempt(msg *Message) func(ctx context.Context, attempt *Attempt) error {
+	return func(ctx context.Context, attempt *Attempt) error {
+		return s.repo.RecordAttempt(ctx, newMessageAttempt(msg.ID, 0, attempt))
+	}
+}
+
+// recordPartAttempt persists provider calls made for one part of a split message
+func (s *Service) recordPartAttempt(msg *Message, part *MessagePart) func(ctx context.Context, attempt *Attempt) error {
+	return func(ctx context.Context, attempt *Attempt) error {
+		return s.repo.RecordAttempt(ctx, newMessageAttempt(msg.ID, part.PartNumber, attempt))
+	}
+}
+
+// handleFailedMessage decides whether a failed message is retried in a later run
+func (s *Service) handleFailedMessage(ctx context.Context, msg *Message, budget *AttemptBudget, err error) error {
+	attempts := msg.AttemptCount + budget.Counted()
+
+	if IsThrottled(err) {
+		logger.Warn("Provider throttled, message requeued",
+			"message_id", msg.ID,
+			"attempts", attempts,
+			"error", err,
+		)
+		return s.repo.UpdateMessageStatusOnly(ctx, msg.ID, MessageStatusQueued)
+	}
+
+	if errors.Is(err, ErrDeliveryInFlight) {
+		return s.repo.UpdateMessageStatusOnly(ctx, msg.ID, MessageStatusQueued)
+	}
+
 	newRetryCount := msg.RetryCount + 1
 
 	logger.Error("Error processing message",
 		"message_id", msg.ID,
-		"retry_count", newRetryCount,
-		"max_retry_attempts", s.maxRetryAttempts,
+		"attempts", attempts,
+		"max_attempts", s.retryPolicy.MaxAttempts,
 		"error", err,
 	)
 
-	if newRetryCount >= s.maxRetryAttempts {
-		logger.Warn("Message exceeded max retry attempts, marking as permanently failed",
+	if IsPermanentFailure(err) {
+		logger.Warn("Message failed permanently, not retrying",
+			"message_id", msg.ID,
+			"attempts", attempts,
+			"error", err,
+		)
+		return s.repo.UpdateMessageStatusAndRetry(ctx, msg.ID, MessageStatusFailed, newRetryCount)
+	}
+
+	if s.retryPolicy.Exhausted(attempts) {
+		logger.Warn("Message exhausted its attempt budget, marking as permanently failed",
 			"message_id", msg.ID,
-			"retry_count", newRetryCount,
-			"max_retry_attempts", s.maxRetryAttempts,
+			"attempts", attempts,
+			"max_attempts", s.retryPolicy.MaxAttempts,
 		)
 		return s.repo.UpdateMessageStatusAndRetry(ctx, msg.ID, MessageStatusFailed, newRetryCount)
 	}
@@ -136,24 +500,191 @@ func (s *Service) handleFailedMessage(ctx context.Context, msg *Message, err err
 
 	logger.Info("Message scheduled for retry",
 		"message_id", msg.ID,
-		"retry_count", newRetryCount,
-		"max_retry_attempts", s.maxRetryAttempts,
+		"attempts", attempts,
+		"max_attempts", s.retryPolicy.MaxAttempts,
 	)
 
 	return nil
 }
 
-// GetSentMessages retrieves sent messages with pagination
-func (s *Service) GetSentMessages(ctx context.Context, limit, offset int) ([]*Message, int64, error) {
-	messages, err := s.repo.GetSentMessages(ctx, limit, offset)
+// GetMessageByProviderID finds a message by the messageId the provider
+// returned, answering from the cache when possible
+func (s *Service) GetMessageByProviderID(ctx context.Context, providerID string) (*ProviderLookupResponse, error) {
+	if cacheRepo := s.cache(); cacheRepo != nil {
+		cached, err := cacheRepo.GetMessageID(ctx, providerID)
+		if err != nil {
+			logger.Warn("Failed to read messageId cache, falling back to database",
+				"message_id", providerID,
+				"error", err,
+			)
+		}
+		if cached != nil && cached.ID != 0 {
+			msg, err := s.repo.GetMessageByID(ctx, cached.ID)
+			if err == nil {
+				return &ProviderLookupResponse{Message: msg, Cached: true, SentAt: &cached.SentAt}, nil
+			}
+			if !errors.Is(err, ErrMessageNotFound) {
+				return nil, &ErrRepository{Operation: "get message", Err: err}
+			}
+		}
+	}
+
+	msg, err := s.repo.GetMessageByProviderID(ctx, providerID)
+	if err != nil {
+		if errors.Is(err, ErrMessageNotFound) {
+			return nil, err
+		}
+		return nil, &ErrRepository{Operation: "get message by provider id", Err: err}
+	}
+	return &ProviderLookupResponse{Message: msg}, nil
+}
+
+// HandleDeliveryReceipt records a provider's delivery receipt on the message
+// or part it sent under receipt.MessageID. A split message is delivered once
+// all of its parts are. Receipts with another status are only logged.
+func (s *Service) HandleDeliveryReceipt(ctx context.Context, receipt *DeliveryReceiptRequest) (*Message, error) {
+	msg, err := s.repo.GetMessageByProviderID(ctx, receipt.MessageID)
+	if err != nil {
+		if errors.Is(err, ErrMessageNotFound) {
+			return nil, err
+		}
+		return nil, &ErrRepository{Operation: "get message by provider id", Err: err}
+	}
+
+	if receipt.Status != string(MessageStatusDelivered) {
+		logger.Info("Delivery receipt received",
+			"message_id", msg.ID,
+			"provider_message_id", receipt.MessageID,
+			"status", receipt.Status,
+		)
+		return msg, nil
+	}
+
+	deliveredAt := receipt.DeliveredAt
+	if deliveredAt.IsZero() {
+		deliveredAt = time.Now().UTC()
+	}
+
+	parts, err := s.repo.GetMessageParts(ctx, msg.ID)
+	if err != nil {
+		return nil, &ErrRepository{Operation: "get message parts", Err: err}
+	}
+
+	delivered := true
+	for _, part := range parts {
+		if part.ProviderID == receipt.MessageID && part.DeliveredAt == nil {
+			if err := s.repo.MarkPartDelivered(ctx, part.ID, deliveredAt); err != nil {
+				return nil, &ErrRepository{Operation: "mark part delivered", Err: err}
+			}
+			part.DeliveredAt = &deliveredAt
+		}
+		if part.DeliveredAt == nil {
+			delivered = false
+		}
+	}
+
+	if delivered && msg.DeliveredAt == nil {
+		if err := s.repo.MarkMessageDelivered(ctx, msg.ID, deliveredAt); err != nil {
+			return nil, &ErrRepository{Operation: "mark message delivered", Err: err}
+		}
+		msg.DeliveredAt = &deliveredAt
+		s.invalidateSent(ctx, msg)
+	}
+
+	return msg, nil
+}
+
+// GetMessageDetail retrieves a message together with its delivery attempts
+func (s *Service) GetMessageDetail(ctx context.Context, id uint) (*MessageDetailResponse, error) {
+	msg, err := s.repo.GetMessageByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrMessageNotFound) {
+			return nil, err
+		}
+		return nil, &ErrRepository{Operation: "get message", Err: err}
+	}
+
+	parts, err := s.repo.GetMessageParts(ctx, id)
 	if err != nil {
-		return nil, 0, &ErrRepository{Operation: "get sent messages", Err: err}
+		return nil, &ErrRepository{Operation: "get message parts", Err: err}
 	}
 
-	total, err := s.repo.CountSentMessages(ctx)
+	attempts, err := s.repo.GetAttempts(ctx, id)
 	if err != nil {
-		return nil, 0, &ErrRepository{Operation: "count sent messages", Err: err}
+		return nil, &ErrRepository{Operation: "get message attempts", Err: err}
 	}
 
-	return messages, total, nil
+	return &MessageDetailResponse{
+		Message:  msg,
+		Parts:    parts,
+		Attempts: attempts,
+	}, nil
+}
+
+// GetSentMessages retrieves sent messages with pagination. Pages and the
+// count are read through the list cache when one is set.
+func (s *Service) GetSentMessages(ctx context.Context, limit, offset int) (*SentMessagesPage, error) {
+	listCache := s.lists()
+	result := &SentMessagesPage{}
+
+	var page *CachedPage
+	var count *CachedCount
+	if listCache != nil {
+		var err error
+		if page, err = listCache.GetSentPage(ctx, limit, offset); err != nil {
+			logger.Warn("Failed to read sent message page from cache", "error", err)
+		}
+		if count, err = listCache.GetSentCount(ctx); err != nil {
+			logger.Warn("Failed to read sent message count from cache", "error", err)
+		}
+	}
+
+	if page != nil {
+		result.Messages = page.Messages
+	} else {
+		messages, err := s.repo.GetSentMessages(ctx, limit, offset)
+		if err != nil {
+			return nil, &ErrRepository{Operation: "get sent messages", Err: err}
+		}
+		result.Messages = messages
+		if listCache != nil {
+			if err := listCache.SetSentPage(ctx, limit, offset, messages); err != nil {
+				logger.Warn("Failed to cache sent message page", "error", err)
+			}
+		}
+	}
+
+	if count != nil {
+		result.Total = count.Total
+	} else {
+		total, err := s.repo.CountSentMessages(ctx)
+		if err != nil {
+			return nil, &ErrRepository{Operation: "count sent messages", Err: err}
+		}
+		result.Total = total
+		if listCache != nil {
+			if err := listCache.SetSentCount(ctx, total); err != nil {
+				logger.Warn("Failed to cache sent message count", "error", err)
+			}
+		}
+	}
+
+	if page != nil && count != nil {
+		result.Cached = true
+		result.CachedAt = page.CachedAt
+		if count.CachedAt.Before(result.CachedAt) {
+			result.CachedAt = count.CachedAt
+		}
+	}
+
+	return result, nil
+}
+
+// FlushListCache drops every cached sent message page and count
+func (s *Service) FlushListCache(ctx context.Context) error {
+	listCache := s.lists()
+	if listCache == nil {
+		return nil
+	}
+	return listCache.Flush(ctx)
 }
@@ -0,0 +1,149 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markerCache is an in-memory CacheRepository
+type markerCache struct {
+	markers map[[2]int]*DeliveryMarker
+}
+
+func newMarkerCache() *markerCache {
+	return &markerCache{markers: map[[2]int]*DeliveryMarker{}}
+}
+
+func (c *markerCache) SetMessageID(ctx context.Context, id uint, messageID string, sentAt time.Time) error {
+	return nil
+}
+
+func (c *markerCache) GetMessageID(ctx context.Context, messageID string) (*CachedMessageID, error) {
+	return nil, nil
+}
+
+func (c *markerCache) BeginDelivery(ctx context.Context, id uint, part int) (*DeliveryMarker, error) {
+	key := [2]int{int(id), part}
+	if marker, ok := c.markers[key]; ok {
+		return marker, nil
+	}
+	c.markers[key] = &DeliveryMarker{State: DeliveryInFlight, UpdatedAt: time.Now()}
+	return nil, nil
+}
+
+func (c *markerCache) CompleteDelivery(ctx context.Context, id uint, part int, providerID string) error {
+	c.markers[[2]int{int(id), part}] = &DeliveryMarker{State: DeliverySent, ProviderID: providerID, UpdatedAt: time.Now()}
+	return nil
+}
+
+func (c *markerCache) ClearDelivery(ctx context.Context, id uint, part int) error {
+	delete(c.markers, [2]int{int(id), part})
+	return nil
+}
+
+// flakyStatusRepo fails the first status update after a send
+type flakyStatusRepo struct {
+	*partsRepo
+	failures int
+}
+
+func (r *flakyStatusRepo) UpdateMessageStatus(ctx context.Context, id uint, status MessageStatus, messageID string) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("connection reset")
+	}
+	return r.partsRepo.UpdateMessageStatus(ctx, id, status, messageID)
+}
+
+// countingWebhook accepts every message
+type countingWebhook struct {
+	calls int
+	err   error
+}
+
+func (w *countingWebhook) SendMessage(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
+	w.calls++
+	if w.err != nil {
+		return nil, w.err
+	}
+	return &WebhookResponse{Message: "Accepted", MessageID: "provider-1"}, nil
+}
+
+func shortMessage() *Message {
+	return &Message{ID: 100, To: "+905551111111", Content: "hello", Status: MessageStatusQueued}
+}
+
+func TestService_ReconcilesInsteadOfResending(t *testing.T) {
+	repo := &flakyStatusRepo{partsRepo: &partsRepo{msg: shortMessage()}, failures: 1}
+	webhook := &countingWebhook{}
+	service := NewService(repo, newMarkerCache(), webhook, 1, 6, false, NewRetryPolicy(4, 1), time.Second)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, MessageStatusQueued, repo.msg.Status)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, 1, webhook.calls)
+	assert.Equal(t, MessageStatusSent, repo.msg.Status)
+	assert.Equal(t, "provider-1", repo.msg.MessageID)
+}
+
+func TestService_WaitsForDeliveryInFlight(t *testing.T) {
+	repo := &partsRepo{msg: shortMessage()}
+	cache := newMarkerCache()
+	cache.markers[[2]int{100, 0}] = &DeliveryMarker{State: DeliveryInFlight, UpdatedAt: time.Now()}
+	webhook := &countingWebhook{}
+	service := NewService(repo, cache, webhook, 1, 6, false, NewRetryPolicy(4, 1), time.Second)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, 0, webhook.calls)
+	assert.Equal(t, MessageStatusQueued, repo.msg.Status)
+	assert.Zero(t, repo.msg.RetryCount)
+}
+
+func TestService_ClearsMarkerWhenProviderRejects(t *testing.T) {
+	repo := &partsRepo{msg: shortMessage()}
+	cache := newMarkerCache()
+	webhook := &countingWebhook{err: &ErrDelivery{Err: errors.New("timeout")}}
+	service := NewService(repo, cache, webhook, 1, 6, false, NewRetryPolicy(4, 1), time.Second)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Empty(t, cache.markers)
+
+	webhook.err = nil
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, 2, webhook.calls)
+	assert.Equal(t, MessageStatusSent, repo.msg.Status)
+}
+
+// invalidationRecorder is a ListCache recording invalidations
+type invalidationRecorder struct {
+	ListCache
+	invalidated []time.Time
+}
+
+func (c *invalidationRecorder) InvalidateSent(ctx context.Context, createdAt time.Time) error {
+	c.invalidated = append(c.invalidated, createdAt)
+	return nil
+}
+
+func TestService_InvalidatesListCacheWhenSent(t *testing.T) {
+	msg := shortMessage()
+	msg.CreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo := &partsRepo{msg: msg}
+	webhook := &countingWebhook{err: &ErrDelivery{Err: errors.New("timeout")}}
+	service := NewService(repo, newMarkerCache(), webhook, 1, 6, false, NewRetryPolicy(4, 1), time.Second)
+	lists := &invalidationRecorder{}
+	service.SetListCache(lists)
+
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Empty(t, lists.invalidated, "failed sends leave the lists alone")
+
+	webhook.err = nil
+	require.NoError(t, service.SendPendingMessages(context.Background()))
+	assert.Equal(t, []time.Time{msg.CreatedAt}, lists.invalidated)
+}
@@ -0,0 +1,42 @@
+package blob
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutOpenDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "attachments/1/a.png", strings.NewReader("content")))
+
+	r, err := store.Open(ctx, "attachments/1/a.png")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	_ = r.Close()
+	assert.Equal(t, "content", string(content))
+
+	require.NoError(t, store.Delete(ctx, "attachments/1/a.png"))
+	require.NoError(t, store.Delete(ctx, "attachments/1/a.png"))
+
+	_, err = store.Open(ctx, "attachments/1/a.png")
+	assert.ErrorIs(t, err, message.ErrAttachmentNotFound)
+}
+
+func TestLocalStore_RejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"../escape", "attachments/../../escape", ""} {
+		assert.Error(t, store.Put(context.Background(), key, strings.NewReader("x")), key)
+	}
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"insider-case/internal/domain/message"
+	"sync"
+	"time"
+)
+
+// Stats holds cache counters
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"` // Entries dropped to stay within capacity
+	Expired   uint64 `json:"expired"`   // Entries dropped after their TTL
+	Entries   int    `json:"entries"`
+}
+
+type entry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process LRU cache with TTL implementing
+// message.CacheRepository. It stands in for Redis when Redis is unavailable
+// and in tests.
+type MemoryCache struct {
+	capacity    int
+	ttl         time.Duration
+	inFlightTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List // Front is the most recently used
+	counter Stats
+}
+
+// NewMemoryCache creates a MemoryCache holding up to capacity entries. Cached
+// message IDs and sent markers expire after ttl, in-flight markers after inFlightTTL.
+func NewMemoryCache(capacity int, ttl, inFlightTTL time.Duration) *MemoryCache {
+	return &MemoryCache{
+		capacity:    capacity,
+		ttl:         ttl,
+		inFlightTTL: inFlightTTL,
+		now:         time.Now,
+		items:       make(map[string]*list.Element),
+		order:       list.New(),
+	}
+}
+
+// SetMessageID caches the messageId with our message ID and sending time
+func (c *MemoryCache) SetMessageID(ctx context.Context, id uint, messageID string, sentAt time.Time) error {
+	c.set(messageKey(messageID), &message.CachedMessageID{ID: id, MessageID: messageID, SentAt: sentAt}, c.ttl)
+	return nil
+}
+
+// GetMessageID retrieves cached messageId information, nil when it is not cached
+func (c *MemoryCache) GetMessageID(ctx context.Context, messageID string) (*message.CachedMessageID, error) {
+	value, ok := c.get(messageKey(messageID))
+	if !ok {
+		return nil, nil
+	}
+	cached := *value.(*message.CachedMessageID)
+	return &cached, nil
+}
+
+// BeginDelivery sets an in-flight marker unless one exists, in which case the existing marker is returned
+func (c *MemoryCache) BeginDelivery(ctx context.Context, id uint, part int) (*message.DeliveryMarker, error) {
+	key := deliveryKey(id, part)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if value, ok := c.lookup(key); ok {
+		marker := *value.(*message.DeliveryMarker)
+		return &marker, nil
+	}
+	c.store(key, &message.DeliveryMarker{State: message.DeliveryInFlight, UpdatedAt: c.now()}, c.inFlightTTL)
+	return nil, nil
+}
+
+// CompleteDelivery replaces the in-flight marker with a sent marker holding the provider message ID
+func (c *MemoryCache) CompleteDelivery(ctx context.Context, id uint, part int, providerID string) error {
+	c.setMarker(id, part, &message.DeliveryMarker{State: message.DeliverySent, ProviderID: providerID, UpdatedAt: c.now()})
+	return nil
+}
+
+// ClearDelivery removes the delivery marker
+func (c *MemoryCache) ClearDelivery(ctx context.Context, id uint, part int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[deliveryKey(id, part)]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the counters
+func (c *MemoryCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.counter
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// setMarker stores a marker as is, with the TTL of its state
+func (c *MemoryCache) setMarker(id uint, part int, marker *message.DeliveryMarker) {
+	ttl := c.ttl
+	if marker.State == message.DeliveryInFlight {
+		ttl = c.inFlightTTL
+	}
+	copied := *marker
+	c.set(deliveryKey(id, part), &copied, ttl)
+}
+
+func (c *MemoryCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(key)
+}
+
+func (c *MemoryCache) set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(key, value, ttl)
+}
+
+// lookup finds a live entry and marks it recently used. Callers hold mu.
+func (c *MemoryCache) lookup(key string) (interface{}, bool) {
+	element, ok := c.items[key]
+	if !ok {
+		c.counter.Misses++
+		return nil, false
+	}
+
+	item := element.Value.(*entry)
+	if !item.expiresAt.IsZero() && !c.now().Before(item.expiresAt) {
+		c.remove(element)
+		c.counter.Expired++
+		c.counter.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	c.counter.Hits++
+	return item.value, true
+}
+
+// store adds or replaces an entry, evicting the least recently used entry when full. Callers hold mu.
+func (c *MemoryCache) store(key string, value interface{}, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if element, ok := c.items[key]; ok {
+		item := element.Value.(*entry)
+		item.value, item.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.counter.Evictions++
+	}
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry).key)
+}
+
+func messageKey(messageID string) string {
+	return fmt.Sprintf("message:%s", messageID)
+}
+
+func deliveryKey(id uint, part int) string {
+	return fmt.Sprintf("delivery:%d:%d", id, part)
+}
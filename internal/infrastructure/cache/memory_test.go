@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock drives the TTL of a MemoryCache
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestMemoryCache(capacity int) (*MemoryCache, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	c := NewMemoryCache(capacity, time.Hour, time.Minute)
+	c.now = clock.Now
+	return c, clock
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestMemoryCache(2)
+
+	require.NoError(t, c.SetMessageID(ctx, 1, "a", time.Now()))
+	require.NoError(t, c.SetMessageID(ctx, 2, "b", time.Now()))
+	cached, _ := c.GetMessageID(ctx, "a") // a is now more recent than b
+	require.NotNil(t, cached)
+	require.NoError(t, c.SetMessageID(ctx, 3, "c", time.Now()))
+
+	cached, _ = c.GetMessageID(ctx, "b")
+	assert.Nil(t, cached)
+	cached, _ = c.GetMessageID(ctx, "a")
+	require.NotNil(t, cached)
+	assert.Equal(t, uint(1), cached.ID)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, 2, stats.Entries)
+}
+
+func TestMemoryCache_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newTestMemoryCache(10)
+
+	require.NoError(t, c.SetMessageID(ctx, 1, "a", time.Now()))
+	clock.now = clock.now.Add(time.Hour)
+
+	cached, err := c.GetMessageID(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+	assert.Equal(t, uint64(1), c.Stats().Expired)
+	assert.Zero(t, c.Stats().Entries)
+}
+
+func TestMemoryCache_DeliveryMarkers(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newTestMemoryCache(10)
+
+	marker, err := c.BeginDelivery(ctx, 7, 0)
+	require.NoError(t, err)
+	assert.Nil(t, marker)
+
+	marker, _ = c.BeginDelivery(ctx, 7, 0)
+	require.NotNil(t, marker)
+	assert.Equal(t, message.DeliveryInFlight, marker.State)
+
+	// In-flight markers expire sooner than sent markers
+	clock.now = clock.now.Add(2 * time.Minute)
+	marker, _ = c.BeginDelivery(ctx, 7, 0)
+	assert.Nil(t, marker)
+
+	require.NoError(t, c.CompleteDelivery(ctx, 7, 0, "provider-1"))
+	clock.now = clock.now.Add(2 * time.Minute)
+	marker, _ = c.BeginDelivery(ctx, 7, 0)
+	require.NotNil(t, marker)
+	assert.Equal(t, message.DeliverySent, marker.State)
+	assert.Equal(t, "provider-1", marker.ProviderID)
+
+	require.NoError(t, c.ClearDelivery(ctx, 7, 0))
+	marker, _ = c.BeginDelivery(ctx, 7, 0)
+	assert.Nil(t, marker)
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TieredStats holds the counters of both cache tiers
+type TieredStats struct {
+	Local        Stats  `json:"local"`
+	Remote       bool   `json:"remote"` // Whether Redis is currently attached
+	RemoteHits   uint64 `json:"remote_hits"`
+	RemoteMisses uint64 `json:"remote_misses"`
+	RemoteErrors uint64 `json:"remote_errors"`
+}
+
+// TieredCache reads from local memory first and writes through to a remote
+// cache (Redis) when one is attached. Delivery markers are decided by the
+// remote cache, which is shared by every instance, and mirrored locally so
+// they survive losing Redis.
+type TieredCache struct {
+	local *MemoryCache
+
+	mu     sync.RWMutex
+	remote message.CacheRepository
+
+	remoteHits   uint64
+	remoteMisses uint64
+	remoteErrors uint64
+}
+
+// NewTieredCache creates a TieredCache, remote may be nil
+func NewTieredCache(local *MemoryCache, remote message.CacheRepository) *TieredCache {
+	return &TieredCache{
+		local:  local,
+		remote: remote,
+	}
+}
+
+// SetRemote attaches or, with nil, detaches the remote cache
+func (c *TieredCache) SetRemote(remote message.CacheRepository) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remote = remote
+}
+
+func (c *TieredCache) remoteCache() message.CacheRepository {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.remote
+}
+
+// SetMessageID caches the messageId locally and remotely
+func (c *TieredCache) SetMessageID(ctx context.Context, id uint, messageID string, sentAt time.Time) error {
+	_ = c.local.SetMessageID(ctx, id, messageID, sentAt)
+
+	if remote := c.remoteCache(); remote != nil {
+		return c.remoteErr(remote.SetMessageID(ctx, id, messageID, sentAt))
+	}
+	return nil
+}
+
+// GetMessageID reads the local cache, then the remote cache, keeping remote hits locally
+func (c *TieredCache) GetMessageID(ctx context.Context, messageID string) (*message.CachedMessageID, error) {
+	if cached, _ := c.local.GetMessageID(ctx, messageID); cached != nil {
+		return cached, nil
+	}
+
+	remote := c.remoteCache()
+	if remote == nil {
+		return nil, nil
+	}
+
+	cached, err := remote.GetMessageID(ctx, messageID)
+	if err != nil {
+		return nil, c.remoteErr(err)
+	}
+	if cached == nil {
+		atomic.AddUint64(&c.remoteMisses, 1)
+		return nil, nil
+	}
+
+	atomic.AddUint64(&c.remoteHits, 1)
+	_ = c.local.SetMessageID(ctx, cached.ID, cached.MessageID, cached.SentAt)
+	return cached, nil
+}
+
+// BeginDelivery asks the remote cache when attached and reachable, the local cache otherwise
+func (c *TieredCache) BeginDelivery(ctx context.Context, id uint, part int) (*message.DeliveryMarker, error) {
+	remote := c.remoteCache()
+	if remote == nil {
+		return c.local.BeginDelivery(ctx, id, part)
+	}
+
+	marker, err := remote.BeginDelivery(ctx, id, part)
+	if err != nil {
+		_ = c.remoteErr(err)
+		return c.local.BeginDelivery(ctx, id, part)
+	}
+
+	if marker == nil {
+		c.local.setMarker(id, part, &message.DeliveryMarker{State: message.DeliveryInFlight, UpdatedAt: time.Now()})
+	} else {
+		c.local.setMarker(id, part, marker)
+	}
+	return marker, nil
+}
+
+// CompleteDelivery records the sent marker locally and remotely
+func (c *TieredCache) CompleteDelivery(ctx context.Context, id uint, part int, providerID string) error {
+	_ = c.local.CompleteDelivery(ctx, id, part, providerID)
+
+	if remote := c.remoteCache(); remote != nil {
+		return c.remoteErr(remote.CompleteDelivery(ctx, id, part, providerID))
+	}
+	return nil
+}
+
+// ClearDelivery removes the marker locally and remotely
+func (c *TieredCache) ClearDelivery(ctx context.Context, id uint, part int) error {
+	_ = c.local.ClearDelivery(ctx, id, part)
+
+	if remote := c.remoteCache(); remote != nil {
+		return c.remoteErr(remote.ClearDelivery(ctx, id, part))
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the counters
+func (c *TieredCache) Stats() TieredStats {
+	return TieredStats{
+		Local:        c.local.Stats(),
+		Remote:       c.remoteCache() != nil,
+		RemoteHits:   atomic.LoadUint64(&c.remoteHits),
+		RemoteMisses: atomic.LoadUint64(&c.remoteMisses),
+		RemoteErrors: atomic.LoadUint64(&c.remoteErrors),
+	}
+}
+
+func (c *TieredCache) remoteErr(err error) error {
+	if err != nil {
+		atomic.AddUint64(&c.remoteErrors, 1)
+	}
+	return err
+}
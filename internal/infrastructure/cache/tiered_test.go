@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"insider-case/internal/domain/message"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableCache fails every call like a Redis that went away
+type unreachableCache struct {
+	message.CacheRepository
+}
+
+var errUnreachable = errors.New("connection refused")
+
+func (unreachableCache) SetMessageID(ctx context.Context, id uint, messageID string, sentAt time.Time) error {
+	return errUnreachable
+}
+
+func (unreachableCache) GetMessageID(ctx context.Context, messageID string) (*message.CachedMessageID, error) {
+	return nil, errUnreachable
+}
+
+func (unreachableCache) BeginDelivery(ctx context.Context, id uint, part int) (*message.DeliveryMarker, error) {
+	return nil, errUnreachable
+}
+
+func TestTieredCache_ReadsLocalFirstAndWritesThrough(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemoryCache(10, time.Hour, time.Minute)
+	tiered := NewTieredCache(NewMemoryCache(10, time.Hour, time.Minute), remote)
+
+	require.NoError(t, tiered.SetMessageID(ctx, 1, "a", time.Now()))
+	cached, _ := remote.GetMessageID(ctx, "a")
+	require.NotNil(t, cached)
+
+	cached, err := tiered.GetMessageID(ctx, "a")
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.Equal(t, uint64(1), tiered.Stats().Local.Hits)
+	assert.Zero(t, tiered.Stats().RemoteHits)
+}
+
+func TestTieredCache_KeepsRemoteHitsLocally(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemoryCache(10, time.Hour, time.Minute)
+	require.NoError(t, remote.SetMessageID(ctx, 1, "a", time.Now()))
+	tiered := NewTieredCache(NewMemoryCache(10, time.Hour, time.Minute), remote)
+
+	for i := 0; i < 2; i++ {
+		cached, err := tiered.GetMessageID(ctx, "a")
+		require.NoError(t, err)
+		require.NotNil(t, cached)
+	}
+
+	stats := tiered.Stats()
+	assert.Equal(t, uint64(1), stats.RemoteHits)
+	assert.Equal(t, uint64(1), stats.Local.Hits)
+	assert.Equal(t, uint64(1), stats.Local.Misses)
+}
+
+func TestTieredCache_FallsBackToLocalMarkers(t *testing.T) {
+	ctx := context.Background()
+	tiered := NewTieredCache(NewMemoryCache(10, time.Hour, time.Minute), unreachableCache{})
+
+	marker, err := tiered.BeginDelivery(ctx, 7, 0)
+	require.NoError(t, err)
+	assert.Nil(t, marker)
+
+	marker, err = tiered.BeginDelivery(ctx, 7, 0)
+	require.NoError(t, err)
+	require.NotNil(t, marker)
+	assert.Equal(t, message.DeliveryInFlight, marker.State)
+	assert.Equal(t, uint64(2), tiered.Stats().RemoteErrors)
+}
+
+func TestTieredCache_MirrorsRemoteMarkers(t *testing.T) {
+	ctx := context.Background()
+	remote := NewMemoryCache(10, time.Hour, time.Minute)
+	tiered := NewTieredCache(NewMemoryCache(10, time.Hour, time.Minute), remote)
+
+	_, err := tiered.BeginDelivery(ctx, 7, 0)
+	require.NoError(t, err)
+	require.NoError(t, tiered.CompleteDelivery(ctx, 7, 0, "provider-1"))
+
+	// Redis is lost, the local mirror still prevents a second send
+	tiered.SetRemote(nil)
+	marker, err := tiered.BeginDelivery(ctx, 7, 0)
+	require.NoError(t, err)
+	require.NotNil(t, marker)
+	assert.Equal(t, message.DeliverySent, marker.State)
+	assert.False(t, tiered.Stats().Remote)
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ArchiveRepository struct {
+	db     *gorm.DB
+	dbType string
+}
+
+// NewArchiveRepository creates the archive repository, dbType selects whether
+// archived rows are locked while they are copied
+func NewArchiveRepository(db *gorm.DB, dbType string) message.ArchiveRepository {
+	return &ArchiveRepository{db: db, dbType: dbType}
+}
+
+func (r *ArchiveRepository) ArchiveMessagesBefore(ctx context.Context, cutoff time.Time, statuses []message.MessageStatus, limit int) (int64, int64, error) {
+	var archived, deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := r.archivable(tx, cutoff, statuses).Order("id").Limit(limit)
+		if r.dbType != constants.DBTypeSQLite {
+			// SQLite has no row locks, its writers are serialized instead
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+
+		var messages []*message.Message
+		if err := query.Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		archives, err := r.newArchives(tx, messages)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&archives).Error; err != nil {
+			return err
+		}
+
+		ids := make([]uint, len(messages))
+		for i, msg := range messages {
+			ids[i] = msg.ID
+		}
+		// Parts and attempts are removed by ON DELETE CASCADE
+		result := tx.Where("id IN ? AND status IN ?", ids, statuses).Delete(&message.Message{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(archives)) {
+			return fmt.Errorf("archived %d messages but deleted %d, rolled back", len(archives), result.RowsAffected)
+		}
+
+		archived, deleted = int64(len(archives)), result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return archived, deleted, nil
+}
+
+func (r *ArchiveRepository) CountArchivable(ctx context.Context, cutoff time.Time, statuses []message.MessageStatus) (int64, error) {
+	var count int64
+	err := r.archivable(r.db.WithContext(ctx), cutoff, statuses).Count(&count).Error
+	return count, err
+}
+
+// RestoreMessage inserts the archived message, parts and attempts with their
+// original IDs and removes them from the archive
+func (r *ArchiveRepository) RestoreMessage(ctx context.Context, id uint) (*message.Message, error) {
+	var payload message.ArchivePayload
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var archived message.ArchivedMessage
+		err := tx.First(&archived, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return message.ErrArchivedNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(archived.Payload), &payload); err != nil {
+			return fmt.Errorf("invalid archive of message %d: %w", id, err)
+		}
+		if payload.Message == nil {
+			return fmt.Errorf("invalid archive of message %d: no message", id)
+		}
+
+		var existing int64
+		if err := tx.Model(&message.Message{}).Where("id = ?", id).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return message.ErrMessageExists
+		}
+
+		if err := tx.Create(payload.Message).Error; err != nil {
+			return err
+		}
+		if len(payload.Parts) > 0 {
+			if err := tx.Create(&payload.Parts).Error; err != nil {
+				return err
+			}
+		}
+		if len(payload.Attempts) > 0 {
+			if err := tx.Create(&payload.Attempts).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&archived).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payload.Message, nil
+}
+
+// archivable selects messages in one of statuses created before cutoff.
+// Messages with attachments wait for the attachment pruner, which owns their
+// stored content.
+func (r *ArchiveRepository) archivable(tx *gorm.DB, cutoff time.Time, statuses []message.MessageStatus) *gorm.DB {
+	return tx.Model(&message.Message{}).
+		Where("status IN ?", statuses).
+		Where("created_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM attachments WHERE attachments.message_id = messages.id)")
+}
+
+// newArchives builds the archive rows of messages with their parts and attempts
+func (r *ArchiveRepository) newArchives(tx *gorm.DB, messages []*message.Message) ([]*message.ArchivedMessage, error) {
+	ids := make([]uint, len(messages))
+	payloads := make(map[uint]*message.ArchivePayload, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+		payloads[msg.ID] = &message.ArchivePayload{Message: msg}
+	}
+
+	var parts []*message.MessagePart
+	if err := tx.Where("message_id IN ?", ids).Order("message_id, part_number").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		payloads[part.MessageID].Parts = append(payloads[part.MessageID].Parts, part)
+	}
+
+	var attempts []*message.MessageAttempt
+	if err := tx.Where("message_id IN ?", ids).Order("message_id, id").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	for _, attempt := range attempts {
+		payloads[attempt.MessageID].Attempts = append(payloads[attempt.MessageID].Attempts, attempt)
+	}
+
+	archivedAt := time.Now().UTC()
+	archives := make([]*message.ArchivedMessage, 0, len(messages))
+	for _, msg := range messages {
+		content, err := json.Marshal(payloads[msg.ID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode message %d: %w", msg.ID, err)
+		}
+		archives = append(archives, &message.ArchivedMessage{
+			ID:         msg.ID,
+			Status:     msg.Status,
+			CreatedAt:  msg.CreatedAt,
+			ArchivedAt: archivedAt,
+			Payload:    string(content),
+		})
+	}
+	return archives, nil
+}
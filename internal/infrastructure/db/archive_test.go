@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createAgedMessage stores a message created age ago
+func createAgedMessage(t *testing.T, database *gorm.DB, status message.MessageStatus, age time.Duration) *message.Message {
+	createdAt := time.Now().Add(-age).UTC()
+	msg := &message.Message{
+		Channel:   constants.ChannelSMS,
+		To:        "+905551111111",
+		Content:   "old message",
+		Status:    status,
+		MessageID: "provider-id",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	require.NoError(t, database.Create(msg).Error)
+	return msg
+}
+
+func TestArchiveRepository_ArchivesAndRestores(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+	repo := NewArchiveRepository(database, constants.DBTypeSQLite)
+	day := 24 * time.Hour
+
+	sent := createAgedMessage(t, database, message.MessageStatusSent, 40*day)
+	require.NoError(t, database.Create(&message.MessagePart{MessageID: sent.ID, PartNumber: 1, TotalParts: 1, Content: "part", Status: message.MessageStatusSent}).Error)
+	require.NoError(t, database.Create(&message.MessageAttempt{MessageID: sent.ID, Provider: "generic", AttemptNumber: 1, RequestedAt: sent.CreatedAt, StatusCode: 202}).Error)
+	failed := createAgedMessage(t, database, message.MessageStatusFailed, 40*day)
+	createAgedMessage(t, database, message.MessageStatusQueued, 40*day)
+	createAgedMessage(t, database, message.MessageStatusSent, day)
+	withAttachment := createAgedMessage(t, database, message.MessageStatusSent, 40*day)
+	require.NoError(t, database.Create(&message.Attachment{MessageID: withAttachment.ID, URL: "https://example.com/a.png", ContentType: "image/png"}).Error)
+
+	cutoff := time.Now().Add(-30 * day)
+	eligible, err := repo.CountArchivable(ctx, cutoff, message.ArchivableStatuses)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), eligible)
+
+	archived, deleted, err := repo.ArchiveMessagesBefore(ctx, cutoff, message.ArchivableStatuses, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+	assert.Equal(t, int64(1), deleted)
+	archived, _, err = repo.ArchiveMessagesBefore(ctx, cutoff, message.ArchivableStatuses, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+	archived, _, err = repo.ArchiveMessagesBefore(ctx, cutoff, message.ArchivableStatuses, 1)
+	require.NoError(t, err)
+	assert.Zero(t, archived)
+
+	var remaining, archives, parts int64
+	require.NoError(t, database.Model(&message.Message{}).Count(&remaining).Error)
+	require.NoError(t, database.Model(&message.ArchivedMessage{}).Count(&archives).Error)
+	require.NoError(t, database.Model(&message.MessagePart{}).Count(&parts).Error)
+	assert.Equal(t, int64(3), remaining)
+	assert.Equal(t, int64(2), archives)
+	assert.Zero(t, parts)
+
+	restored, err := repo.RestoreMessage(ctx, sent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sent.ID, restored.ID)
+	assert.Equal(t, message.MessageStatusSent, restored.Status)
+	assert.WithinDuration(t, sent.CreatedAt, restored.CreatedAt, time.Millisecond)
+
+	var restoredParts []*message.MessagePart
+	require.NoError(t, database.Where("message_id = ?", sent.ID).Find(&restoredParts).Error)
+	require.Len(t, restoredParts, 1)
+	var attempts int64
+	require.NoError(t, database.Model(&message.MessageAttempt{}).Where("message_id = ?", sent.ID).Count(&attempts).Error)
+	assert.Equal(t, int64(1), attempts)
+
+	_, err = repo.RestoreMessage(ctx, sent.ID)
+	assert.ErrorIs(t, err, message.ErrArchivedNotFound)
+
+	require.NoError(t, database.Create(&message.Message{ID: failed.ID, To: "+905552222222", Content: "reused id", Status: message.MessageStatusQueued}).Error)
+	_, err = repo.RestoreMessage(ctx, failed.ID)
+	assert.ErrorIs(t, err, message.ErrMessageExists)
+}
+
+func TestArchiver_ArchivesAndRestoresPartialMessages(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+	repo := NewArchiveRepository(database, constants.DBTypeSQLite)
+	day := 24 * time.Hour
+
+	partial := createAgedMessage(t, database, message.MessageStatusPartial, 40*day)
+	require.NoError(t, database.Create(&message.MessagePart{MessageID: partial.ID, PartNumber: 1, TotalParts: 2, Content: "first", Status: message.MessageStatusSent}).Error)
+	require.NoError(t, database.Create(&message.MessagePart{MessageID: partial.ID, PartNumber: 2, TotalParts: 2, Content: "second", Status: message.MessageStatusFailed}).Error)
+
+	dryRun := message.NewArchiver(repo, nil, 30*day, 10, true)
+	require.NoError(t, dryRun.Run(ctx))
+	assert.Equal(t, int64(1), dryRun.Stats().Eligible)
+
+	archiver := message.NewArchiver(repo, nil, 30*day, 10, false)
+	require.NoError(t, archiver.Run(ctx))
+	assert.Equal(t, uint64(1), archiver.Stats().Archived)
+	assert.Equal(t, uint64(1), archiver.Stats().Deleted)
+
+	var remaining int64
+	require.NoError(t, database.Model(&message.Message{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+
+	restored, err := archiver.Restore(ctx, partial.ID)
+	require.NoError(t, err)
+	assert.Equal(t, message.MessageStatusPartial, restored.Status)
+	assert.Equal(t, uint64(1), archiver.Stats().Restored)
+
+	var parts []*message.MessagePart
+	require.NoError(t, database.Where("message_id = ?", partial.ID).Order("part_number").Find(&parts).Error)
+	require.Len(t, parts, 2)
+	assert.Equal(t, message.MessageStatusFailed, parts[1].Status)
+}
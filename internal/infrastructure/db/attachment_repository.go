@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"insider-case/internal/domain/message"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) message.AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, attachment *message.Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *AttachmentRepository) GetAttachments(ctx context.Context, messageID uint) ([]*message.Attachment, error) {
+	var attachments []*message.Attachment
+	err := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("id ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *AttachmentRepository) GetAttachmentByKey(ctx context.Context, key string) (*message.Attachment, error) {
+	var attachment message.Attachment
+	err := r.db.WithContext(ctx).Where("storage_key = ?", key).First(&attachment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, message.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetAttachmentsBefore returns attachments created before cutoff whose message
+// is no longer waiting to be sent
+func (r *AttachmentRepository) GetAttachmentsBefore(ctx context.Context, cutoff time.Time, limit int) ([]*message.Attachment, error) {
+	var attachments []*message.Attachment
+	err := r.db.WithContext(ctx).
+		Joins("JOIN messages ON messages.id = attachments.message_id").
+		Where("attachments.created_at < ?", cutoff).
+		Where("messages.status NOT IN ?", []message.MessageStatus{message.MessageStatusQueued, message.MessageStatusProcessing}).
+		Order("attachments.id").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *AttachmentRepository) DeleteAttachment(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&message.Attachment{}, id).Error
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"insider-case/internal/config"
 	"insider-case/internal/constants"
@@ -10,12 +12,18 @@ import (
 	gormLogger "gorm.io/gorm/logger"
 )
 
+// ErrUnsupportedDBType is returned for a DB_TYPE without a connector and query executor
+var ErrUnsupportedDBType = errors.New("unsupported database type")
+
 type Connector interface {
 	Connect() (*gorm.DB, error)
 }
 
 func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
-	connector := NewConnector(&cfg.Database)
+	connector, err := NewConnector(&cfg.Database)
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := connector.Connect()
 	if err != nil {
@@ -48,15 +56,39 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err := RunMigrations(db); err != nil {
+	if err := RunMigrations(db, &cfg.Database); err != nil {
 		logger.Error("Failed to run database migrations", "error", err)
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if cfg.Database.SeedOnStart {
+		if err := seedOnStart(db, cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
-func NewConnector(dbCfg *config.DatabaseConfig) Connector {
+// seedOnStart loads the pending fixtures, skipping them in production
+func seedOnStart(db *gorm.DB, cfg *config.Config) error {
+	result, err := SeedFixtures(context.Background(), db, cfg)
+	if errors.Is(err, ErrSeedInProduction) {
+		logger.Warn("SEED_ON_START is ignored in production")
+		return nil
+	}
+	if err != nil {
+		logger.Error("Failed to load fixtures", "error", err)
+		return fmt.Errorf("failed to load fixtures: %w", err)
+	}
+
+	logger.Info("Fixtures loaded", "applied", result.Applied, "skipped", result.Skipped, "messages", result.Messages)
+	return nil
+}
+
+// NewConnector returns the connector of the configured database type, an
+// empty type means Postgres
+func NewConnector(dbCfg *config.DatabaseConfig) (Connector, error) {
 	dbType := dbCfg.Type
 	if dbType == "" {
 		dbType = constants.DBTypePostgres
@@ -64,9 +96,14 @@ func NewConnector(dbCfg *config.DatabaseConfig) Connector {
 
 	switch dbType {
 	case constants.DBTypePostgres:
-		return NewPostgresConnector(dbCfg)
+		return NewPostgresConnector(dbCfg), nil
+	case constants.DBTypeSQLite:
+		return NewSQLiteConnector(dbCfg), nil
+	case constants.DBTypeMySQL:
+		return NewMySQLConnector(dbCfg), nil
 	default:
-		return &unsupportedConnector{dbType: dbType}
+		logger.Error("Unsupported database type", "db_type", dbType)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDBType, dbType)
 	}
 }
 
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConnector_SupportedTypes(t *testing.T) {
+	for _, dbType := range []string{"", constants.DBTypePostgres, constants.DBTypeSQLite, constants.DBTypeMySQL} {
+		connector, err := NewConnector(&config.DatabaseConfig{Type: dbType})
+		require.NoError(t, err, dbType)
+		assert.NotNil(t, connector, dbType)
+
+		repo, err := NewRepository(nil, dbType)
+		require.NoError(t, err, dbType)
+		assert.NotNil(t, repo, dbType)
+	}
+}
+
+func TestNewConnector_RejectsUnsupportedType(t *testing.T) {
+	_, err := NewConnector(&config.DatabaseConfig{Type: "oracle"})
+	assert.ErrorIs(t, err, ErrUnsupportedDBType)
+
+	_, err = NewRepository(nil, "oracle")
+	assert.ErrorIs(t, err, ErrUnsupportedDBType)
+}
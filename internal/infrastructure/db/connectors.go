@@ -3,8 +3,11 @@ package db
 import (
 	"fmt"
 	"insider-case/internal/config"
-	"insider-case/internal/pkg/logger"
+	"os"
+	"path/filepath"
 
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -21,11 +24,31 @@ func (c *PostgresConnector) Connect() (*gorm.DB, error) {
 	return gorm.Open(postgres.Open(c.cfg.GetDSN()), gormConfig(c.cfg))
 }
 
-type unsupportedConnector struct {
-	dbType string
+type MySQLConnector struct {
+	cfg *config.DatabaseConfig
+}
+
+func NewMySQLConnector(cfg *config.DatabaseConfig) Connector {
+	return &MySQLConnector{cfg: cfg}
+}
+
+func (c *MySQLConnector) Connect() (*gorm.DB, error) {
+	return gorm.Open(mysql.Open(c.cfg.GetMySQLDSN()), gormConfig(c.cfg))
+}
+
+type SQLiteConnector struct {
+	cfg *config.DatabaseConfig
+}
+
+func NewSQLiteConnector(cfg *config.DatabaseConfig) Connector {
+	return &SQLiteConnector{cfg: cfg}
 }
 
-func (c *unsupportedConnector) Connect() (*gorm.DB, error) {
-	logger.Error("Unsupported database type", "db_type", c.dbType)
-	return nil, fmt.Errorf("unsupported database type: %s", c.dbType)
+func (c *SQLiteConnector) Connect() (*gorm.DB, error) {
+	if dir := filepath.Dir(c.cfg.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+	return gorm.Open(sqlite.Open(c.cfg.GetSQLiteDSN()), gormConfig(c.cfg))
 }
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/fixtures"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Fixture errors
+var (
+	ErrSeedInProduction = errors.New("fixtures are never loaded in production")
+	ErrFixtureModified  = errors.New("loaded fixture was modified")
+)
+
+// fixtureFileName matches 0001_sample_messages.yaml, .yml and .json
+var fixtureFileName = regexp.MustCompile(`^(\d+)_(.+)\.(ya?ml|json)$`)
+
+// Fixture is a versioned file of sample data
+type Fixture struct {
+	Version  int64            `json:"-" yaml:"-"`
+	Name     string           `json:"-" yaml:"-"`
+	Checksum string           `json:"-" yaml:"-"` // SHA-256 of the file
+	Messages []FixtureMessage `json:"messages" yaml:"messages"`
+}
+
+// FixtureMessage is a message to insert, empty fields take the model defaults
+type FixtureMessage struct {
+	Channel   string `json:"channel" yaml:"channel"`
+	To        string `json:"to" yaml:"to"`
+	Subject   string `json:"subject" yaml:"subject"`
+	Content   string `json:"content" yaml:"content"`
+	Status    string `json:"status" yaml:"status"`
+	MessageID string `json:"message_id" yaml:"message_id"`
+}
+
+// SeedResult reports what a seed run did
+type SeedResult struct {
+	Applied  int // Fixture files loaded by this run
+	Skipped  int // Fixture files loaded by an earlier run
+	Messages int // Messages inserted by this run
+}
+
+// seedFixture is a row of seed_fixtures, which records every loaded fixture
+type seedFixture struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string `gorm:"size:64"`
+	AppliedAt time.Time
+}
+
+func (seedFixture) TableName() string {
+	return "seed_fixtures"
+}
+
+// FixtureFiles returns the fixtures embedded in the binary, or those in dir
+// when an external directory is given
+func FixtureFiles(dir string) (fs.FS, error) {
+	if dir == "" {
+		return fixtures.FS, nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixtures directory %s is not a directory", dir)
+	}
+	logger.Info("Using external fixtures directory", "path", dir)
+	return os.DirFS(dir), nil
+}
+
+// SeedFixtures loads the pending fixtures of the configured directory. It
+// refuses to run in production.
+func SeedFixtures(ctx context.Context, db *gorm.DB, cfg *config.Config) (*SeedResult, error) {
+	if cfg.Env == constants.EnvProduction {
+		return nil, ErrSeedInProduction
+	}
+
+	files, err := FixtureFiles(cfg.Database.FixturesDir)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFixtures(ctx, db, files)
+}
+
+// LoadFixtures inserts every fixture in files that was not loaded before, in
+// version order. Each fixture is inserted in one transaction together with its
+// seed_fixtures row, so running it again is a no-op. A loaded fixture whose
+// file changed fails the run; add a new fixture instead.
+func LoadFixtures(ctx context.Context, db *gorm.DB, files fs.FS) (*SeedResult, error) {
+	list, err := readFixtures(files)
+	if err != nil {
+		return nil, err
+	}
+
+	db = db.WithContext(ctx)
+	if err := db.AutoMigrate(&seedFixture{}); err != nil {
+		return nil, fmt.Errorf("failed to create seed_fixtures: %w", err)
+	}
+
+	var rows []seedFixture
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read seed_fixtures: %w", err)
+	}
+	loaded := make(map[int64]seedFixture, len(rows))
+	for _, row := range rows {
+		loaded[row.Version] = row
+	}
+
+	result := &SeedResult{}
+	for _, fixture := range list {
+		if row, ok := loaded[fixture.Version]; ok {
+			if row.Checksum != fixture.Checksum {
+				return result, fmt.Errorf("%w: %d_%s", ErrFixtureModified, fixture.Version, fixture.Name)
+			}
+			result.Skipped++
+			continue
+		}
+
+		if err := loadFixture(db, fixture); err != nil {
+			return result, err
+		}
+		logger.Info("Fixture loaded", "version", fixture.Version, "name", fixture.Name, "messages", len(fixture.Messages))
+		result.Applied++
+		result.Messages += len(fixture.Messages)
+	}
+
+	return result, nil
+}
+
+// readFixtures parses the fixture files in files, sorted by version. Other
+// files are ignored.
+func readFixtures(files fs.FS) ([]*Fixture, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	byVersion := make(map[int64]*Fixture)
+	for _, entry := range entries {
+		match := fixtureFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixture version in %s: %w", entry.Name(), err)
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("fixture version %d is used by %s and %s", version, existing.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", entry.Name(), err)
+		}
+
+		fixture := &Fixture{}
+		if path.Ext(entry.Name()) == ".json" {
+			err = json.Unmarshal(content, fixture)
+		} else {
+			err = yaml.Unmarshal(content, fixture)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", entry.Name(), err)
+		}
+
+		sum := sha256.Sum256(content)
+		fixture.Version = version
+		fixture.Name = match[2]
+		fixture.Checksum = hex.EncodeToString(sum[:])
+		byVersion[version] = fixture
+	}
+
+	list := make([]*Fixture, 0, len(byVersion))
+	for _, fixture := range byVersion {
+		list = append(list, fixture)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+func loadFixture(db *gorm.DB, fixture *Fixture) error {
+	messages := make([]*message.Message, 0, len(fixture.Messages))
+	for _, m := range fixture.Messages {
+		msg := &message.Message{
+			Channel:   m.Channel,
+			To:        m.To,
+			Subject:   m.Subject,
+			Content:   m.Content,
+			Status:    message.MessageStatus(m.Status),
+			MessageID: m.MessageID,
+		}
+		if msg.Channel == "" {
+			msg.Channel = constants.ChannelSMS
+		}
+		if msg.Status == "" {
+			msg.Status = message.MessageStatusQueued
+		}
+		messages = append(messages, msg)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(messages) > 0 {
+			if err := tx.Create(&messages).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&seedFixture{
+			Version:   fixture.Version,
+			Name:      fixture.Name,
+			Checksum:  fixture.Checksum,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load fixture %d_%s: %w", fixture.Version, fixture.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFixtures() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_sms.yaml":   {Data: []byte("messages:\n  - to: \"+905551111111\"\n    content: first\n  - to: \"+905552222222\"\n    content: second\n")},
+		"0002_email.json": {Data: []byte(`{"messages":[{"channel":"email","to":"a@example.com","subject":"Hi","content":"third","status":"sent"}]}`)},
+		"README.md":       {Data: []byte("not a fixture")},
+	}
+}
+
+func TestLoadFixtures_LoadsOnce(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+	files := testFixtures()
+
+	result, err := LoadFixtures(ctx, database, files)
+	require.NoError(t, err)
+	assert.Equal(t, &SeedResult{Applied: 2, Messages: 3}, result)
+
+	var messages []*message.Message
+	require.NoError(t, database.Order("id").Find(&messages).Error)
+	require.Len(t, messages, 3)
+	assert.Equal(t, constants.ChannelSMS, messages[0].Channel)
+	assert.Equal(t, message.MessageStatusQueued, messages[0].Status)
+	assert.Equal(t, constants.ChannelEmail, messages[2].Channel)
+	assert.Equal(t, message.MessageStatusSent, messages[2].Status)
+
+	files["0003_more.yml"] = &fstest.MapFile{Data: []byte("messages:\n  - to: \"+905553333333\"\n    content: fourth\n")}
+	result, err = LoadFixtures(ctx, database, files)
+	require.NoError(t, err)
+	assert.Equal(t, &SeedResult{Applied: 1, Skipped: 2, Messages: 1}, result)
+
+	var count int64
+	require.NoError(t, database.Model(&message.Message{}).Count(&count).Error)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestLoadFixtures_DetectsModifiedFixture(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t)
+	files := testFixtures()
+
+	_, err := LoadFixtures(ctx, database, files)
+	require.NoError(t, err)
+
+	files["0001_sms.yaml"] = &fstest.MapFile{Data: []byte("messages: []\n")}
+	_, err = LoadFixtures(ctx, database, files)
+	assert.ErrorIs(t, err, ErrFixtureModified)
+}
+
+func TestLoadFixtures_RejectsInvalidFile(t *testing.T) {
+	database := openSQLite(t)
+
+	_, err := LoadFixtures(context.Background(), database, fstest.MapFS{
+		"0001_broken.json": {Data: []byte("{")},
+	})
+	assert.Error(t, err)
+}
+
+func TestSeedFixtures_RefusesProduction(t *testing.T) {
+	database := openSQLite(t)
+
+	_, err := SeedFixtures(context.Background(), database, &config.Config{Env: constants.EnvProduction})
+	assert.ErrorIs(t, err, ErrSeedInProduction)
+
+	var count int64
+	require.NoError(t, database.Model(&message.Message{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
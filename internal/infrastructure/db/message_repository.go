@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"insider-case/internal/constants"
 	"insider-case/internal/domain/message"
 	"insider-case/internal/infrastructure/db/repository"
-	"insider-case/internal/pkg/logger"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,29 +17,39 @@ type Repository struct {
 	queryExecutor repository.QueryExecutor
 }
 
-func NewRepository(db *gorm.DB, dbType string) message.Repository {
+// NewRepository creates the message repository with the claim query of dbType,
+// an empty type means Postgres
+func NewRepository(db *gorm.DB, dbType string) (message.Repository, error) {
 	if dbType == "" {
 		dbType = constants.DBTypePostgres
 	}
 
+	queryExecutor, err := newQueryExecutor(dbType)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Repository{
 		db:            db,
-		queryExecutor: newQueryExecutor(dbType),
-	}
+		queryExecutor: queryExecutor,
+	}, nil
 }
 
-func newQueryExecutor(dbType string) repository.QueryExecutor {
+func newQueryExecutor(dbType string) (repository.QueryExecutor, error) {
 	switch dbType {
 	case constants.DBTypePostgres:
-		return repository.NewPostgresExecutor()
+		return repository.NewPostgresExecutor(), nil
+	case constants.DBTypeSQLite:
+		return repository.NewSQLiteExecutor(), nil
+	case constants.DBTypeMySQL:
+		return repository.NewMySQLExecutor(), nil
 	default:
-		logger.Warn("Unsupported database type, falling back to PostgreSQL", "db_type", dbType)
-		return repository.NewPostgresExecutor()
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDBType, dbType)
 	}
 }
 
-func (r *Repository) GetUnsentMessages(ctx context.Context, limit int, maxRetryAttempts int) ([]*message.Message, error) {
-	return r.queryExecutor.GetUnsentMessages(ctx, r.db, limit, maxRetryAttempts)
+func (r *Repository) GetUnsentMessages(ctx context.Context, limit int) ([]*message.Message, error) {
+	return r.queryExecutor.GetUnsentMessages(ctx, r.db, limit)
 }
 
 func (r *Repository) UpdateMessageStatus(ctx context.Context, id uint, status message.MessageStatus, messageID string) error {
@@ -67,9 +79,20 @@ func (r *Repository) UpdateMessageStatusAndRetry(ctx context.Context, id uint, s
 		}).Error
 }
 
+func (r *Repository) UpdateMessageEncoding(ctx context.Context, id uint, encoding string, segmentCount int) error {
+	return r.db.WithContext(ctx).
+		Model(&message.Message{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"encoding":      encoding,
+			"segment_count": segmentCount,
+		}).Error
+}
+
 func (r *Repository) GetSentMessages(ctx context.Context, limit, offset int) ([]*message.Message, error) {
 	var messages []*message.Message
 	err := r.db.WithContext(ctx).
+		Preload("Attachments").
 		Where("status = ?", message.MessageStatusSent).
 		Order("created_at DESC").
 		Limit(limit).
@@ -98,3 +121,144 @@ func (r *Repository) CountSentMessages(ctx context.Context) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
+func (r *Repository) GetMessageByID(ctx context.Context, id uint) (*message.Message, error) {
+	var msg message.Message
+	err := r.db.WithContext(ctx).Preload("Attachments").First(&msg, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, message.ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// GetMessagesByIDs retrieves the messages with the given IDs, missing IDs are skipped
+func (r *Repository) GetMessagesByIDs(ctx context.Context, ids []uint) ([]*message.Message, error) {
+	var messages []*message.Message
+	err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+// GetMessageByProviderID retrieves a message by the provider messageId of the
+// message or of one of its parts
+func (r *Repository) GetMessageByProviderID(ctx context.Context, providerID string) (*message.Message, error) {
+	var msg message.Message
+	err := r.db.WithContext(ctx).
+		Preload("Attachments").
+		Where("message_id = ?", providerID).
+		Or("id IN (?)", r.db.Model(&message.MessagePart{}).Select("message_id").Where("provider_id = ?", providerID)).
+		First(&msg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, message.ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// MarkMessageDelivered stores the delivery time of a message, keeping the
+// first one when a receipt is repeated
+func (r *Repository) MarkMessageDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&message.Message{}).
+		Where("id = ? AND delivered_at IS NULL", id).
+		Update("delivered_at", deliveredAt).Error
+}
+
+func (r *Repository) GetMessageParts(ctx context.Context, messageID uint) ([]*message.MessagePart, error) {
+	var parts []*message.MessagePart
+	err := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("part_number ASC").
+		Find(&parts).Error
+	return parts, err
+}
+
+func (r *Repository) CreateMessageParts(ctx context.Context, parts []*message.MessagePart) error {
+	return r.db.WithContext(ctx).Create(&parts).Error
+}
+
+func (r *Repository) UpdateMessagePart(ctx context.Context, part *message.MessagePart) error {
+	return r.db.WithContext(ctx).
+		Model(&message.MessagePart{}).
+		Where("id = ?", part.ID).
+		Updates(map[string]interface{}{
+			"status":        part.Status,
+			"provider_id":   part.ProviderID,
+			"attempt_count": part.AttemptCount,
+			"call_count":    part.CallCount,
+			"error":         part.Error,
+		}).Error
+}
+
+// MarkPartDelivered stores the delivery time of a message part, keeping the
+// first one when a receipt is repeated
+func (r *Repository) MarkPartDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&message.MessagePart{}).
+		Where("id = ? AND delivered_at IS NULL", id).
+		Update("delivered_at", deliveredAt).Error
+}
+
+// RecordAttempt stores the attempt and counts the call on the message in the
+// same transaction. Unless the provider throttled the call, it also counts
+// against the message's attempt budget. Attempts of a split message count
+// against their part, which is kept by UpdateMessagePart.
+func (r *Repository) RecordAttempt(ctx context.Context, attempt *message.MessageAttempt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		if attempt.PartNumber > 0 {
+			return nil
+		}
+		updates := map[string]interface{}{
+			"call_count":      gorm.Expr("call_count + 1"),
+			"last_attempt_at": attempt.RequestedAt,
+		}
+		if !attempt.Throttled {
+			updates["attempt_count"] = gorm.Expr("attempt_count + 1")
+		}
+		return tx.Model(&message.Message{}).
+			Where("id = ?", attempt.MessageID).
+			Updates(updates).Error
+	})
+}
+
+func (r *Repository) GetAttempts(ctx context.Context, messageID uint) ([]*message.MessageAttempt, error) {
+	var attempts []*message.MessageAttempt
+	err := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("attempt_number ASC, id ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// DeleteAttemptsBefore deletes up to limit attempts created before cutoff. The
+// batch is selected first because MySQL allows neither LIMIT in an IN subquery
+// nor a subquery on the table being deleted from.
+func (r *Repository) DeleteAttemptsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	var ids []uint
+	if err := r.db.WithContext(ctx).
+		Model(&message.MessageAttempt{}).
+		Where("created_at < ?", cutoff).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Delete(&message.MessageAttempt{})
+	return result.RowsAffected, result.Error
+}
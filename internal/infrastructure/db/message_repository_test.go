@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"insider-case/internal/constants"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// statementRecorder is a GORM logger collecting the SQL of every statement
+type statementRecorder struct {
+	gormlogger.Interface
+	mu         sync.Mutex
+	statements []string
+}
+
+func (r *statementRecorder) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return r
+}
+
+func (r *statementRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, sql)
+}
+
+// openDryRun opens a dialect without connecting, statements are only built
+func openDryRun(t *testing.T, dialector gorm.Dialector) (*gorm.DB, *statementRecorder) {
+	recorder := &statementRecorder{Interface: gormlogger.Discard}
+	database, err := gorm.Open(dialector, &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               recorder,
+	})
+	require.NoError(t, err)
+	return database, recorder
+}
+
+// MySQL rejects LIMIT in an IN subquery (1235) and a subquery on the table
+// being deleted from (1093), so pruning must not build one on any dialect
+func TestDeleteAttemptsBefore_NoLimitedSubquery(t *testing.T) {
+	dialectors := map[string]gorm.Dialector{
+		constants.DBTypePostgres: postgres.New(postgres.Config{DSN: "host=localhost"}),
+		constants.DBTypeMySQL:    mysql.New(mysql.Config{DSN: "user@tcp(localhost:3306)/db", SkipInitializeWithVersion: true}),
+	}
+	for dbType, dialector := range dialectors {
+		database, recorder := openDryRun(t, dialector)
+		repo, err := NewRepository(database, dbType)
+		require.NoError(t, err, dbType)
+
+		_, err = repo.DeleteAttemptsBefore(context.Background(), time.Now(), 10)
+		require.NoError(t, err, dbType)
+
+		require.NotEmpty(t, recorder.statements, dbType)
+		for _, statement := range recorder.statements {
+			assert.NotContains(t, strings.ToUpper(statement), "(SELECT", "%s: %s", dbType, statement)
+		}
+	}
+}
@@ -1,146 +1,180 @@
 package db
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
 	"insider-case/internal/domain/message"
 	"insider-case/internal/pkg/logger"
+	"insider-case/migrations"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"gorm.io/gorm"
 )
 
-func RunMigrations(db *gorm.DB) error {
-	logger.Info("Running database migrations...")
-
-	if err := runSQLMigrations(db); err != nil {
-		logger.Warn("SQL migration failed, continuing with AutoMigrate", "error", err)
-	}
-
-	if err := db.AutoMigrate(&message.Message{}); err != nil {
-		return fmt.Errorf("failed to run AutoMigrate: %w", err)
+// RunMigrations applies the pending SQL migrations of the configured dialect,
+// then AutoMigrate unless it is disabled
+func RunMigrations(db *gorm.DB, dbCfg *config.DatabaseConfig) error {
+	dbType := dbCfg.Type
+	if dbType == "" {
+		dbType = constants.DBTypePostgres
 	}
+	logger.Info("Running database migrations...", "db_type", dbType)
 
-	logger.Info("Database migrations completed successfully")
-	return nil
-}
-
-func runSQLMigrations(db *gorm.DB) error {
-	migrationDirs := []string{"migrations", "./migrations", "/app/migrations", filepath.Join(".", "migrations")}
-
-	var migrationsDir string
-	for _, dir := range migrationDirs {
-		if info, err := os.Stat(dir); err == nil && info.IsDir() {
-			migrationsDir = dir
-			logger.Info("Found migrations directory", "path", dir)
-			break
-		}
+	files, err := MigrationFiles(dbType, dbCfg.MigrationsDir)
+	if err != nil {
+		return err
 	}
-
-	if migrationsDir == "" {
-		logger.Warn("Migrations directory not found, using AutoMigrate only")
-		return nil
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
-	entries, err := os.ReadDir(migrationsDir)
+	migrator, err := NewMigrator(sqlDB, dbType, files)
 	if err != nil {
-		logger.Warn("Could not read migrations directory, using AutoMigrate only", "error", err)
-		return nil
+		return err
 	}
-
-	var sqlFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".sql") {
-			sqlFiles = append(sqlFiles, entry.Name())
-		}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		return err
 	}
+	logger.Info("SQL migrations applied", "count", applied)
 
-	if len(sqlFiles) == 0 {
-		logger.Info("No SQL migration files found, using AutoMigrate only")
+	if !dbCfg.AutoMigrate {
+		logger.Info("AutoMigrate disabled, schema is managed by SQL migrations only")
 		return nil
 	}
-
-	sort.Strings(sqlFiles)
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
-	}
-
-	for _, fileName := range sqlFiles {
-		if err := executeMigrationFile(sqlDB, migrationsDir, fileName); err != nil {
-			return err
-		}
+	if err := db.AutoMigrate(&message.Message{}, &message.MessagePart{}, &message.MessageAttempt{}, &message.Attachment{}, &message.ArchivedMessage{}); err != nil {
+		return fmt.Errorf("failed to run AutoMigrate: %w", err)
 	}
 
+	logger.Info("Database migrations completed successfully")
 	return nil
 }
 
-func executeMigrationFile(sqlDB *sql.DB, migrationsDir, fileName string) error {
-	migrationPath := filepath.Join(migrationsDir, fileName)
-	sqlContent, err := os.ReadFile(migrationPath)
+// MigrationFiles returns the migrations of dbType embedded in the binary, or
+// those in dir/<dbType> when an external directory is given. Each dialect keeps
+// its own copy of every migration under the same file name.
+func MigrationFiles(dbType, dir string) (fs.FS, error) {
+	if dir != "" {
+		path := filepath.Join(dir, dbType)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("migrations directory %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("migrations directory %s is not a directory", path)
+		}
+		logger.Info("Using external migrations directory", "path", path)
+		return os.DirFS(path), nil
+	}
+
+	files, err := fs.Sub(migrations.FS, dbType)
 	if err != nil {
-		logger.Warn("Could not read migration file", "file", migrationPath, "error", err)
-		return nil
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
 	}
+	if entries, err := fs.ReadDir(files, "."); err != nil || len(entries) == 0 {
+		return nil, fmt.Errorf("no embedded migrations for %s", dbType)
+	}
+	return files, nil
+}
 
-	logger.Info("Executing SQL migration", "file", fileName)
+// splitSQLStatements splits a script on semicolons outside of quotes, dollar
+// quoted bodies ($$ ... $$, $tag$ ... $tag$) and comments. Comments are dropped.
+func splitSQLStatements(sql string) []string {
+	var statements []string
+	current := strings.Builder{}
 
-	statements := splitSQLStatements(string(sqlContent))
-	for i, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
 		}
+		current.Reset()
+	}
 
-		if _, err := sqlDB.Exec(stmt); err != nil {
-			errMsg := strings.ToLower(err.Error())
-			if strings.Contains(errMsg, "already exists") || strings.Contains(errMsg, "duplicate") ||
-				(strings.Contains(errMsg, "relation") && strings.Contains(errMsg, "already exists")) {
-				logger.Info("Statement already applied, skipping", "file", fileName, "statement", i+1)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+				continue
+			}
+			i += end
+			current.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+				continue
+			}
+			i += end + 3
+			current.WriteByte(' ')
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedEnd(sql, i, c)
+			current.WriteString(sql[i:end])
+			i = end - 1
+		case c == '$':
+			tag, ok := dollarTag(sql[i:])
+			if !ok {
+				current.WriteByte(c)
 				continue
 			}
-			logger.Warn("SQL migration execution warning", "file", fileName, "statement", i+1, "error", err)
-			return fmt.Errorf("failed to execute migration %s (statement %d): %w", fileName, i+1, err)
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				current.WriteString(sql[i:])
+				i = len(sql)
+				continue
+			}
+			end += i + 2*len(tag)
+			current.WriteString(sql[i:end])
+			i = end - 1
+		case c == ';':
+			current.WriteByte(c)
+			flush()
+		default:
+			current.WriteByte(c)
 		}
 	}
+	flush()
 
-	logger.Info("SQL migration executed successfully", "file", fileName)
-	return nil
+	return statements
 }
 
-func splitSQLStatements(sql string) []string {
-	statements := []string{}
-	current := strings.Builder{}
-
-	lines := strings.Split(sql, "\n")
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+// quotedEnd returns the index after the quote closing the one at start. A
+// doubled quote is an escaped quote.
+func quotedEnd(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] != quote {
 			continue
 		}
-
-		current.WriteString(line)
-		current.WriteString("\n")
-
-		if strings.HasSuffix(trimmed, ";") {
-			stmt := strings.TrimSpace(current.String())
-			if stmt != "" {
-				statements = append(statements, stmt)
-			}
-			current.Reset()
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
 		}
+		return i + 1
 	}
+	return len(sql)
+}
 
-	if current.Len() > 0 {
-		stmt := strings.TrimSpace(current.String())
-		if stmt != "" {
-			statements = append(statements, stmt)
+// dollarTag returns the $tag$ opening a dollar quoted string. Positional
+// parameters like $1 are not tags.
+func dollarTag(sql string) (string, bool) {
+	for i := 1; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '$':
+			return sql[:i+1], true
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return "", false
 		}
 	}
-
-	return statements
+	return "", false
 }
@@ -0,0 +1,537 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"insider-case/internal/constants"
+	"insider-case/internal/pkg/logger"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration errors
+var (
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	ErrNoDownMigration  = errors.New("migration has no down script")
+)
+
+// migrationFileName matches 0001_create_messages_table.up.sql and its .down.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change with its up and optional down script
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of the up script
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // Applied with a different checksum than the file has now
+	Missing   bool // Recorded as applied but no longer on disk
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies versioned SQL migrations and records them in
+// schema_migrations. Each migration runs in its own transaction and an
+// advisory lock keeps replicas from migrating concurrently.
+type Migrator struct {
+	db         *sql.DB
+	dialect    *migrationDialect
+	migrations []*Migration
+}
+
+// NewMigrator creates a Migrator for the migrations in files, which holds the
+// scripts of one dialect
+func NewMigrator(db *sql.DB, dbType string, files fs.FS) (*Migrator, error) {
+	dialect, err := newMigrationDialect(dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := LoadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// LoadMigrations reads NNNN_name.up.sql and NNNN_name.down.sql files, ordered by version
+func LoadMigrations(files fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			logger.Warn("Skipping file without a migration name", "file", entry.Name())
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			sum := sha256.Sum256(content)
+			migration.Up = string(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration and returns how many were applied. It
+// refuses to run when an applied migration was modified.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last steps applied migrations and returns how many were rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Redo reverts the last applied migration and applies it again, leaving any
+// other pending migration alone. It returns the migration, nil when none was applied.
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				redone = m.migrations[i]
+				break
+			}
+		}
+		if redone == nil {
+			return nil
+		}
+		if err := m.revert(ctx, conn, redone); err != nil {
+			return err
+		}
+		return m.apply(ctx, conn, redone)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redone, nil
+}
+
+// Status lists every known migration and whether it is applied. It only
+// reads schema_migrations and does not wait for the migration lock, so it
+// reports progress while another process migrates.
+func (m *Migrator) Status(ctx context.Context) ([]*MigrationStatus, error) {
+	var statuses []*MigrationStatus
+	err := m.withConn(ctx, func(conn *sql.Conn) error {
+		var tables int
+		if err := conn.QueryRowContext(ctx, m.dialect.tableExists).Scan(&tables); err != nil {
+			return fmt.Errorf("failed to look up schema_migrations: %w", err)
+		}
+		applied := make(map[int64]*appliedMigration)
+		if tables > 0 {
+			var err error
+			if applied, err = m.applied(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		known := make(map[int64]bool, len(m.migrations))
+		for _, migration := range m.migrations {
+			known[migration.Version] = true
+			status := &MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if record, ok := applied[migration.Version]; ok {
+				appliedAt := record.AppliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+				status.Modified = record.Checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+
+		for version, record := range applied {
+			if known[version] {
+				continue
+			}
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, &MigrationStatus{
+				Version:   version,
+				Name:      record.Name,
+				Applied:   true,
+				AppliedAt: &appliedAt,
+				Missing:   true,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// withLock runs fn on a single connection holding the migration lock, with
+// schema_migrations in place
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	return m.withConn(ctx, func(conn *sql.Conn) error {
+		if err := m.dialect.lock(ctx, conn); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer func() {
+			if err := m.dialect.unlock(context.Background(), conn); err != nil {
+				logger.Warn("Failed to release migration lock", "error", err)
+			}
+		}()
+
+		if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+
+		return fn(conn)
+	})
+}
+
+// withConn runs fn on a single connection
+func (m *Migrator) withConn(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]*appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]*appliedMigration)
+	for rows.Next() {
+		var version int64
+		record := &appliedMigration{}
+		if err := rows.Scan(&version, &record.Name, &record.Checksum, &record.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = record
+	}
+	return applied, rows.Err()
+}
+
+// verify fails when an applied migration no longer matches its file
+func (m *Migrator) verify(applied map[int64]*appliedMigration) error {
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		record, ok := applied[migration.Version]
+		if ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+
+	for version, record := range applied {
+		if !known[version] {
+			logger.Warn("Applied migration not found in migration files", "version", version, "name", record.Name)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration *Migration) error {
+	logger.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+
+	insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+		m.dialect.placeholder(1), m.dialect.placeholder(2), m.dialect.placeholder(3), m.dialect.placeholder(4))
+
+	return m.inTx(ctx, conn, migration, migration.Up, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, insert, migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration *Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+	}
+	logger.Info("Reverting migration", "version", migration.Version, "name", migration.Name)
+
+	remove := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", m.dialect.placeholder(1))
+
+	return m.inTx(ctx, conn, migration, migration.Down, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, remove, migration.Version)
+		return err
+	})
+}
+
+// inTx runs script and record in one transaction. MySQL commits DDL
+// implicitly, so a failed MySQL migration may be applied in part.
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, migration *Migration, script string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	defer func() { _ = tx.Rollback() }() // No-op after commit
+
+	for i, stmt := range splitSQLStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to execute migration %d_%s (statement %d): %w", migration.Version, migration.Name, i+1, err)
+		}
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// migrationLockID is the Postgres advisory lock key, migrationLockName the MySQL lock name
+const (
+	migrationLockID      = 4_861_202_517
+	migrationLockName    = "insider_case_migrations"
+	migrationLockTimeout = 10 * time.Minute
+)
+
+// migrationDialect holds the database specific parts of the migrator
+type migrationDialect struct {
+	createTable string
+	tableExists string // Counts schema_migrations tables, without creating one
+	placeholder func(n int) string
+	lock        func(ctx context.Context, conn *sql.Conn) error
+	unlock      func(ctx context.Context, conn *sql.Conn) error
+}
+
+func newMigrationDialect(dbType string) (*migrationDialect, error) {
+	switch dbType {
+	case constants.DBTypePostgres, "":
+		return &migrationDialect{
+			createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version BIGINT PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				checksum CHAR(64) NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL
+			)`,
+			tableExists: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'",
+			placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+			lock: func(ctx context.Context, conn *sql.Conn) error {
+				_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID)
+				return err
+			},
+			unlock: func(ctx context.Context, conn *sql.Conn) error {
+				_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+				return err
+			},
+		}, nil
+	case constants.DBTypeMySQL:
+		return &migrationDialect{
+			createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version BIGINT PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				checksum CHAR(64) NOT NULL,
+				applied_at DATETIME(3) NOT NULL
+			)`,
+			tableExists: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'",
+			placeholder: func(int) string { return "?" },
+			lock: func(ctx context.Context, conn *sql.Conn) error {
+				var acquired sql.NullInt64
+				if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&acquired); err != nil {
+					return err
+				}
+				if acquired.Int64 != 1 {
+					return fmt.Errorf("timed out after %s", migrationLockTimeout)
+				}
+				return nil
+			},
+			unlock: func(ctx context.Context, conn *sql.Conn) error {
+				_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+				return err
+			},
+		}, nil
+	case constants.DBTypeSQLite:
+		// SQLite serialises writers on the database file and runs on one node,
+		// there is no advisory lock to take
+		noop := func(context.Context, *sql.Conn) error { return nil }
+		return &migrationDialect{
+			createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INTEGER PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				checksum CHAR(64) NOT NULL,
+				applied_at DATETIME NOT NULL
+			)`,
+			tableExists: "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+			placeholder: func(int) string { return "?" },
+			lock:        noop,
+			unlock:      noop,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDBType, dbType)
+	}
+}
+
+// migrationNameCleaner replaces everything but lowercase letters, digits and underscores
+var migrationNameCleaner = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// CreateMigration writes empty up and down scripts for the next version into
+// every dialect directory under dir and returns their paths
+func CreateMigration(dir, name string) ([]string, error) {
+	name = strings.Trim(migrationNameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is empty")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var dialects []string
+	var latest int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		migrations, err := LoadMigrations(os.DirFS(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		dialects = append(dialects, entry.Name())
+		if n := len(migrations); n > 0 && migrations[n-1].Version > latest {
+			latest = migrations[n-1].Version
+		}
+	}
+	if len(dialects) == 0 {
+		return nil, fmt.Errorf("no dialect directories in %s", dir)
+	}
+
+	base := fmt.Sprintf("%04d_%s", latest+1, name)
+	var created []string
+	for _, dialect := range dialects {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, dialect, fmt.Sprintf("%s.%s.sql", base, direction))
+			content := fmt.Sprintf("-- %s %s migration for %s\n", base, direction, dialect)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return created, fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			created = append(created, path)
+		}
+	}
+	return created, nil
+}
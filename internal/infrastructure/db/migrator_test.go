@@ -0,0 +1,288 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openEmptySQLite(t *testing.T) *sql.DB {
+	database, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), gormConfig(&config.DatabaseConfig{LogLevel: "silent"}))
+	require.NoError(t, err)
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	return sqlDB
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_widgets.up.sql":   {Data: []byte("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT);")},
+		"0001_create_widgets.down.sql": {Data: []byte("DROP TABLE widgets;")},
+		"0002_seed_widgets.up.sql":     {Data: []byte("INSERT INTO widgets (name) VALUES ('a;b'); -- trailing; comment\nINSERT INTO widgets (name) VALUES ('c');")},
+		"0002_seed_widgets.down.sql":   {Data: []byte("DELETE FROM widgets;")},
+		"README.md":                    {Data: []byte("not a migration")},
+	}
+}
+
+func countWidgets(t *testing.T, sqlDB *sql.DB) int {
+	var count int
+	require.NoError(t, sqlDB.QueryRow("SELECT COUNT(*) FROM widgets").Scan(&count))
+	return count
+}
+
+func TestMigrator_AppliesPendingOnce(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, testMigrations())
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, 2, countWidgets(t, sqlDB))
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+	assert.Equal(t, 2, countWidgets(t, sqlDB))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.NotNil(t, status.AppliedAt)
+		assert.False(t, status.Modified)
+	}
+}
+
+func TestMigrator_StatusDoesNotWrite(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, testMigrations())
+	require.NoError(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+
+	var tables int
+	require.NoError(t, sqlDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables))
+	assert.Zero(t, tables)
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	files := testMigrations()
+	files["0003_broken.up.sql"] = &fstest.MapFile{Data: []byte("INSERT INTO widgets (name) VALUES ('d');\nINSERT INTO missing_table VALUES (1);")}
+
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, files)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	require.Error(t, err)
+	assert.Equal(t, 2, countWidgets(t, sqlDB), "statements of the failed migration are rolled back")
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, statuses[2].Applied)
+}
+
+func TestMigrator_DetectsModifiedMigration(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, testMigrations())
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	files := testMigrations()
+	files["0002_seed_widgets.up.sql"] = &fstest.MapFile{Data: []byte("INSERT INTO widgets (name) VALUES ('changed');")}
+	migrator, err = NewMigrator(sqlDB, constants.DBTypeSQLite, files)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[1].Modified)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, testMigrations())
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	assert.Zero(t, countWidgets(t, sqlDB))
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, 2, countWidgets(t, sqlDB))
+}
+
+func TestMigrator_RedoLeavesPendingMigrations(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, testMigrations())
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	files := testMigrations()
+	files["0003_more_widgets.up.sql"] = &fstest.MapFile{Data: []byte("INSERT INTO widgets (name) VALUES ('d');")}
+	migrator, err = NewMigrator(sqlDB, constants.DBTypeSQLite, files)
+	require.NoError(t, err)
+
+	redone, err := migrator.Redo(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, redone)
+	assert.Equal(t, int64(2), redone.Version)
+	assert.Equal(t, 2, countWidgets(t, sqlDB))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[1].Applied)
+	assert.False(t, statuses[2].Applied, "pending migrations are not applied by redo")
+}
+
+func TestMigrator_DownWithoutScript(t *testing.T) {
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	files := testMigrations()
+	delete(files, "0002_seed_widgets.down.sql")
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, files)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	_, err = migrator.Down(ctx, 1)
+	assert.ErrorIs(t, err, ErrNoDownMigration)
+}
+
+func TestMigrator_RepositoryMigrationsRevertCleanly(t *testing.T) {
+	for _, dbType := range []string{constants.DBTypePostgres, constants.DBTypeSQLite, constants.DBTypeMySQL} {
+		files, err := MigrationFiles(dbType, "")
+		require.NoError(t, err, dbType)
+		migrations, err := LoadMigrations(files)
+		require.NoError(t, err, dbType)
+		require.NotEmpty(t, migrations, dbType)
+		for _, migration := range migrations {
+			assert.NotEmpty(t, migration.Down, "%s %d_%s", dbType, migration.Version, migration.Name)
+		}
+	}
+
+	ctx := context.Background()
+	sqlDB := openEmptySQLite(t)
+	files, err := MigrationFiles(constants.DBTypeSQLite, "")
+	require.NoError(t, err)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, files)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 11, reverted)
+
+	var tables int
+	require.NoError(t, sqlDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('messages', 'message_parts', 'message_attempts', 'attachments', 'message_archives')").Scan(&tables))
+	assert.Zero(t, tables)
+}
+
+func TestSplitSQLStatements(t *testing.T) {
+	script := `-- leading comment
+CREATE TABLE t (v TEXT DEFAULT 'x;y');
+/* block; comment */
+INSERT INTO "odd;name" VALUES ('it''s; fine');
+DO $$
+BEGIN
+    EXECUTE format('SELECT 1; SELECT %L', 'a');
+END $$;
+CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;
+SELECT $1, $2;
+SELECT 1`
+
+	statements := splitSQLStatements(script)
+	require.Len(t, statements, 6)
+	assert.Equal(t, "CREATE TABLE t (v TEXT DEFAULT 'x;y');", statements[0])
+	assert.Equal(t, `INSERT INTO "odd;name" VALUES ('it''s; fine');`, statements[1])
+	assert.Contains(t, statements[2], "EXECUTE format('SELECT 1; SELECT %L', 'a');\nEND $$;")
+	assert.Equal(t, "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;", statements[3])
+	assert.Equal(t, "SELECT $1, $2;", statements[4])
+	assert.Equal(t, "SELECT 1", statements[5])
+}
+
+func TestMigrationFiles_Embedded(t *testing.T) {
+	for _, dbType := range []string{constants.DBTypePostgres, constants.DBTypeSQLite, constants.DBTypeMySQL} {
+		files, err := MigrationFiles(dbType, "")
+		require.NoError(t, err, dbType)
+
+		migrations, err := LoadMigrations(files)
+		require.NoError(t, err, dbType)
+		assert.Len(t, migrations, 11, dbType)
+	}
+
+	_, err := MigrationFiles("oracle", "")
+	assert.Error(t, err)
+}
+
+func TestMigrationFiles_ExternalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, constants.DBTypeSQLite), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, constants.DBTypeSQLite, "0001_only.up.sql"), []byte("SELECT 1;"), 0o600))
+
+	files, err := MigrationFiles(constants.DBTypeSQLite, dir)
+	require.NoError(t, err)
+	migrations, err := LoadMigrations(files)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.Equal(t, "only", migrations[0].Name)
+
+	_, err = MigrationFiles(constants.DBTypePostgres, dir)
+	assert.Error(t, err, "a missing directory fails instead of falling back")
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, dialect := range []string{constants.DBTypePostgres, constants.DBTypeSQLite} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, dialect), 0o750))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, constants.DBTypeSQLite, "0003_existing.up.sql"), []byte("SELECT 1;"), 0o600))
+
+	created, err := CreateMigration(dir, "Add Index-On Status")
+	require.NoError(t, err)
+	assert.Len(t, created, 4)
+
+	for _, dialect := range []string{constants.DBTypePostgres, constants.DBTypeSQLite} {
+		migrations, err := LoadMigrations(os.DirFS(filepath.Join(dir, dialect)))
+		require.NoError(t, err, dialect)
+		last := migrations[len(migrations)-1]
+		assert.Equal(t, int64(4), last.Version, dialect)
+		assert.Equal(t, "add_index_on_status", last.Name, dialect)
+	}
+
+	_, err = CreateMigration(dir, "--")
+	assert.Error(t, err)
+}
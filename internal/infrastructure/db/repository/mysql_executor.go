@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLExecutor claims messages on MySQL 8 and MariaDB 10.6+, which support
+// SKIP LOCKED but not UPDATE ... RETURNING. The batch is locked, updated and
+// read back in one transaction.
+type MySQLExecutor struct{}
+
+func NewMySQLExecutor() QueryExecutor {
+	return &MySQLExecutor{}
+}
+
+func (e *MySQLExecutor) GetUnsentMessages(ctx context.Context, db interface{}, limit int) ([]*message.Message, error) {
+	gormDB := db.(*gorm.DB)
+	var messages []*message.Message
+
+	err := gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&message.Message{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", message.MessageStatusQueued).
+			Order("created_at ASC").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&message.Message{}).
+			Where("id IN ?", ids).
+			Update("status", message.MessageStatusProcessing).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id IN ?", ids).
+			Order("created_at ASC").
+			Find(&messages).Error
+	})
+
+	return messages, err
+}
@@ -13,27 +13,25 @@ func NewPostgresExecutor() QueryExecutor {
 	return &PostgresExecutor{}
 }
 
-func (e *PostgresExecutor) GetUnsentMessages(ctx context.Context, db interface{}, limit int, maxRetryAttempts int) ([]*message.Message, error) {
+func (e *PostgresExecutor) GetUnsentMessages(ctx context.Context, db interface{}, limit int) ([]*message.Message, error) {
 	gormDB := db.(*gorm.DB)
 	var messages []*message.Message
 
 	query := `
 		UPDATE messages
-		SET status = $5
+		SET status = $3
 		WHERE id IN (
 			SELECT id FROM messages
-			WHERE (status = $1 OR (status = $2 AND retry_count < $3))
+			WHERE status = $1
 			ORDER BY created_at ASC
-			LIMIT $4
+			LIMIT $2
 			FOR UPDATE SKIP LOCKED
 		)
-		RETURNING id, "to", content, status, message_id, retry_count, created_at, updated_at
+		RETURNING id, channel, "to", subject, content, status, message_id, encoding, segment_count, retry_count, attempt_count, call_count, last_attempt_at, delivered_at, created_at, updated_at
 	`
 
 	return messages, gormDB.WithContext(ctx).Raw(query,
 		message.MessageStatusQueued,
-		message.MessageStatusFailed,
-		maxRetryAttempts,
 		limit,
 		message.MessageStatusProcessing,
 	).Scan(&messages).Error
@@ -6,5 +6,5 @@ import (
 )
 
 type QueryExecutor interface {
-	GetUnsentMessages(ctx context.Context, db interface{}, limit int, maxRetryAttempts int) ([]*message.Message, error)
+	GetUnsentMessages(ctx context.Context, db interface{}, limit int) ([]*message.Message, error)
 }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+
+	"gorm.io/gorm"
+)
+
+// SQLiteExecutor claims messages without SKIP LOCKED. SQLite serialises
+// writers, so each candidate is claimed with an UPDATE that only matches while
+// the row is still queued; rows another process claimed first are skipped.
+type SQLiteExecutor struct{}
+
+func NewSQLiteExecutor() QueryExecutor {
+	return &SQLiteExecutor{}
+}
+
+func (e *SQLiteExecutor) GetUnsentMessages(ctx context.Context, db interface{}, limit int) ([]*message.Message, error) {
+	gormDB := db.(*gorm.DB)
+	var messages []*message.Message
+
+	err := gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var candidates []uint
+		if err := tx.Model(&message.Message{}).
+			Where("status = ?", message.MessageStatusQueued).
+			Order("created_at ASC").
+			Limit(limit).
+			Pluck("id", &candidates).Error; err != nil {
+			return err
+		}
+
+		var claimed []uint
+		for _, id := range candidates {
+			result := tx.Model(&message.Message{}).
+				Where("id = ? AND status = ?", id, message.MessageStatusQueued).
+				Update("status", message.MessageStatusProcessing)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				claimed = append(claimed, id)
+			}
+		}
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		return tx.Where("id IN ?", claimed).
+			Order("created_at ASC").
+			Find(&messages).Error
+	})
+
+	return messages, err
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"insider-case/fixtures"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+// openSQLite migrates a fresh SQLite database with the repository's migrations,
+// without AutoMigrate so the SQL files alone must match the models
+func openSQLite(t *testing.T) *gorm.DB {
+	cfg := &config.DatabaseConfig{
+		Type:     constants.DBTypeSQLite,
+		Path:     filepath.Join(t.TempDir(), "messages.db"),
+		LogLevel: "silent",
+	}
+	connector, err := NewConnector(cfg)
+	require.NoError(t, err)
+	database, err := connector.Connect()
+	require.NoError(t, err)
+
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	files, err := MigrationFiles(constants.DBTypeSQLite, "")
+	require.NoError(t, err)
+	migrator, err := NewMigrator(sqlDB, constants.DBTypeSQLite, files)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 11, applied)
+	return database
+}
+
+// openSeededSQLite is openSQLite with the embedded sample fixtures loaded
+func openSeededSQLite(t *testing.T) *gorm.DB {
+	database := openSQLite(t)
+	result, err := LoadFixtures(context.Background(), database, fixtures.FS)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Messages)
+	return database
+}
+
+func TestSQLite_MigrationsDoNotSeed(t *testing.T) {
+	database := openSQLite(t)
+
+	var count int64
+	require.NoError(t, database.Model(&message.Message{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestSQLite_ClaimsEachMessageOnce(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewRepository(openSeededSQLite(t), constants.DBTypeSQLite)
+	require.NoError(t, err)
+
+	first, err := repo.GetUnsentMessages(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+	for _, msg := range first {
+		assert.Equal(t, message.MessageStatusProcessing, msg.Status)
+	}
+
+	second, err := repo.GetUnsentMessages(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, second, 2)
+	assert.NotContains(t, []uint{first[0].ID, first[1].ID, first[2].ID}, second[0].ID)
+
+	rest, err := repo.GetUnsentMessages(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+}
+
+func TestSQLite_CascadesDeletes(t *testing.T) {
+	ctx := context.Background()
+	database := openSeededSQLite(t)
+	repo, err := NewRepository(database, constants.DBTypeSQLite)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.CreateMessageParts(ctx, []*message.MessagePart{
+		{MessageID: 1, PartNumber: 1, TotalParts: 1, Content: "part", Status: message.MessageStatusQueued},
+	}))
+	require.NoError(t, database.Delete(&message.Message{}, 1).Error)
+
+	parts, err := repo.GetMessageParts(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, parts)
+}
+
+func TestSQLite_NumbersAttemptsAfterThrottledCalls(t *testing.T) {
+	ctx := context.Background()
+	database := openSeededSQLite(t)
+	repo, err := NewRepository(database, constants.DBTypeSQLite)
+	require.NoError(t, err)
+
+	policy := message.NewRetryPolicy(3, 1)
+	record := func(ctx context.Context, attempt *message.Attempt) error {
+		return repo.RecordAttempt(ctx, &message.MessageAttempt{
+			MessageID:     1,
+			AttemptNumber: attempt.Number,
+			RequestedAt:   attempt.RequestedAt,
+			Throttled:     attempt.Throttled,
+		})
+	}
+	run := func(throttled bool) {
+		var msg message.Message
+		require.NoError(t, database.First(&msg, 1).Error)
+		budget := policy.NewBudget(&msg, record)
+		require.True(t, budget.Allow())
+		attempt := budget.Start()
+		attempt.Throttled = throttled
+		require.NoError(t, budget.Record(ctx, attempt))
+	}
+
+	run(true)
+	run(false)
+
+	attempts, err := repo.GetAttempts(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 1, attempts[0].AttemptNumber)
+	assert.True(t, attempts[0].Throttled)
+	assert.Equal(t, 2, attempts[1].AttemptNumber)
+
+	var msg message.Message
+	require.NoError(t, database.First(&msg, 1).Error)
+	assert.Equal(t, 1, msg.AttemptCount)
+	assert.Equal(t, 2, msg.CallCount)
+}
+
+func TestSQLite_RecordsDeliveryReceiptsPerPart(t *testing.T) {
+	ctx := context.Background()
+	database := openSeededSQLite(t)
+	repo, err := NewRepository(database, constants.DBTypeSQLite)
+	require.NoError(t, err)
+	service := message.NewService(repo, nil, nil, 1, 6, true, message.NewRetryPolicy(4, 2), time.Second)
+
+	require.NoError(t, repo.CreateMessageParts(ctx, []*message.MessagePart{
+		{MessageID: 1, PartNumber: 1, TotalParts: 2, Content: "one", Status: message.MessageStatusSent, ProviderID: "p-1"},
+		{MessageID: 1, PartNumber: 2, TotalParts: 2, Content: "two", Status: message.MessageStatusSent, ProviderID: "p-2"},
+	}))
+	require.NoError(t, repo.UpdateMessageStatus(ctx, 1, message.MessageStatusSent, "p-1"))
+
+	first := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg, err := service.HandleDeliveryReceipt(ctx, &message.DeliveryReceiptRequest{MessageID: "p-1", Status: "delivered", DeliveredAt: first})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), msg.ID)
+	assert.Nil(t, msg.DeliveredAt, "the second part is not delivered yet")
+
+	last := first.Add(time.Minute)
+	_, err = service.HandleDeliveryReceipt(ctx, &message.DeliveryReceiptRequest{MessageID: "p-2", Status: "delivered", DeliveredAt: last})
+	require.NoError(t, err)
+	_, err = service.HandleDeliveryReceipt(ctx, &message.DeliveryReceiptRequest{MessageID: "p-2", Status: "delivered", DeliveredAt: last.Add(time.Hour)})
+	require.NoError(t, err)
+
+	parts, err := repo.GetMessageParts(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	require.NotNil(t, parts[0].DeliveredAt)
+	assert.True(t, parts[0].DeliveredAt.Equal(first))
+	require.NotNil(t, parts[1].DeliveredAt)
+	assert.True(t, parts[1].DeliveredAt.Equal(last), "a repeated receipt keeps the first delivery time")
+
+	stored, err := repo.GetMessageByID(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, stored.DeliveredAt)
+	assert.True(t, stored.DeliveredAt.Equal(last))
+	assert.Equal(t, message.MessageStatusSent, stored.Status)
+
+	_, err = service.HandleDeliveryReceipt(ctx, &message.DeliveryReceiptRequest{MessageID: "unknown", Status: "delivered"})
+	assert.ErrorIs(t, err, message.ErrMessageNotFound)
+}
+
+func TestSQLite_DeletesAttemptsInBatches(t *testing.T) {
+	ctx := context.Background()
+	database := openSeededSQLite(t)
+	repo, err := NewRepository(database, constants.DBTypeSQLite)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-48 * time.Hour)
+	for i := 1; i <= 5; i++ {
+		createdAt := old
+		if i == 5 {
+			createdAt = time.Now()
+		}
+		require.NoError(t, database.Create(&message.MessageAttempt{MessageID: 1, AttemptNumber: i, RequestedAt: createdAt, CreatedAt: createdAt}).Error)
+	}
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	deleted, err := repo.DeleteAttemptsBefore(ctx, cutoff, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	deleted, err = repo.DeleteAttemptsBefore(ctx, cutoff, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	deleted, err = repo.DeleteAttemptsBefore(ctx, cutoff, 3)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	var remaining int64
+	require.NoError(t, database.Model(&message.MessageAttempt{}).Count(&remaining).Error)
+	assert.Equal(t, int64(1), remaining)
+}
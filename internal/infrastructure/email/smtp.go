@@ -0,0 +1,209 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// providerName identifies SMTP in recorded delivery attempts
+const providerName = "smtp"
+
+// SMTPSender delivers email messages through an SMTP relay. Every SMTP
+// transaction is one delivery attempt; retries are left to the service.
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string // Header From, may include a display name
+	envelope string // Bare MAIL FROM address
+	startTLS bool
+	timeout  time.Duration
+}
+
+// NewSMTPSender creates a new SMTPSender
+func NewSMTPSender(cfg *config.EmailConfig) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("SMTP host cannot be empty")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP from address: %w", err)
+	}
+
+	return &SMTPSender{
+		addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		host:     cfg.Host,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     from.String(),
+		envelope: from.Address,
+		startTLS: cfg.StartTLS,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+// SendMessage sends req as a plain text email and records the SMTP transaction
+// in the attempt budget carried by ctx
+func (s *SMTPSender) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	budget := message.AttemptBudgetFromContext(ctx)
+	if !budget.Allow() {
+		return nil, message.ErrAttemptBudgetExhausted
+	}
+
+	messageID := newMessageID(s.envelope)
+	attempt := budget.Start()
+	attempt.Provider = providerName
+	err := s.send(ctx, messageID, req)
+	attempt.Latency = time.Since(attempt.RequestedAt)
+	attempt.Err = err
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		attempt.StatusCode = protoErr.Code
+		attempt.ResponseBody = protoErr.Msg
+	}
+
+	if recordErr := budget.Record(ctx, attempt); recordErr != nil {
+		logger.Warn("Failed to record delivery attempt", "attempt", attempt.Number, "error", recordErr)
+	}
+
+	if err != nil {
+		logger.Error("Failed to send email", "to", req.To, "error", err)
+		return nil, classify(err)
+	}
+
+	return &message.WebhookResponse{
+		Message:   "queued",
+		MessageID: messageID,
+	}, nil
+}
+
+func (s *SMTPSender) send(ctx context.Context, messageID string, req *message.WebhookRequest) error {
+	dialer := &net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if s.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if s.startTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	to, err := mail.ParseAddress(req.To)
+	if err != nil {
+		return &textproto.Error{Code: 553, Msg: fmt.Sprintf("invalid recipient address: %v", err)}
+	}
+
+	if err := client.Mail(s.envelope); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("RCPT TO rejected: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(buildMessage(s.from, to, messageID, req)); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders a plain text RFC 5322 message with a quoted-printable
+// body. The To header is rendered from the parsed recipient, so it names the
+// same address as RCPT TO.
+func buildMessage(from string, to *mail.Address, messageID string, req *message.WebhookRequest) []byte {
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", req.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + messageID + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = qp.Write([]byte(req.Content))
+	_ = qp.Close()
+	return buf.Bytes()
+}
+
+// classify wraps an SMTP failure in a message.ErrDelivery. 5xx replies are
+// permanent, 4xx replies and connection problems are transient.
+func classify(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return &message.ErrDelivery{
+			Permanent: protoErr.Code >= 500,
+			Code:      strconv.Itoa(protoErr.Code),
+			Err:       err,
+		}
+	}
+	return &message.ErrDelivery{Err: err}
+}
+
+// newMessageID returns a unique Message-ID in the domain of the sender address
+func newMessageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b) + "@" + domain
+}
@@ -0,0 +1,211 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+// fakeSMTPServer is a minimal SMTP stand-in that records received messages
+// and answers RCPT TO with rcptReply
+type fakeSMTPServer struct {
+	listener  net.Listener
+	rcptReply string
+
+	mu       sync.Mutex
+	from     string
+	rcpt     string
+	messages []string
+}
+
+func newFakeSMTPServer(t *testing.T, rcptReply string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener, rcptReply: rcptReply}
+	go server.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 fake.smtp ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-fake.smtp")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = line[len("MAIL FROM:"):]
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = line[len("RCPT TO:"):]
+			s.mu.Unlock()
+			reply(s.rcptReply)
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) config() *config.EmailConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return &config.EmailConfig{
+		Host:    host,
+		Port:    port,
+		From:    "Insider <noreply@example.com>",
+		Timeout: 5 * time.Second,
+	}
+}
+
+func TestSMTPSender_SendMessage(t *testing.T) {
+	server := newFakeSMTPServer(t, "250 OK")
+	sender, err := NewSMTPSender(server.config())
+	require.NoError(t, err)
+
+	var recorded []*message.Attempt
+	budget := message.NewRetryPolicy(3, 1).NewBudget(&message.Message{}, func(ctx context.Context, attempt *message.Attempt) error {
+		recorded = append(recorded, attempt)
+		return nil
+	})
+
+	resp, err := sender.SendMessage(message.WithAttemptBudget(context.Background(), budget), &message.WebhookRequest{
+		To:      "customer@example.org",
+		Subject: "Siparişiniz yolda",
+		Content: "Merhaba, siparişiniz kargoya verildi.",
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(resp.MessageID, "@example.com"))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.True(t, strings.HasPrefix(server.from, "<noreply@example.com>"))
+	assert.Equal(t, "<customer@example.org>", server.rcpt)
+	require.Len(t, server.messages, 1)
+	assert.Contains(t, server.messages[0], "Subject: =?utf-8?q?Sipari=C5=9Finiz_yolda?=")
+	assert.Contains(t, server.messages[0], "Message-ID: <"+resp.MessageID+">")
+	assert.Contains(t, server.messages[0], "sipari=C5=9Finiz")
+
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "smtp", recorded[0].Provider)
+}
+
+func TestSMTPSender_ToHeaderMatchesEnvelope(t *testing.T) {
+	server := newFakeSMTPServer(t, "250 OK")
+	sender, err := NewSMTPSender(server.config())
+	require.NoError(t, err)
+
+	_, err = sender.SendMessage(context.Background(), &message.WebhookRequest{
+		To:      "  Ayşe Yılmaz   <customer@example.org> ",
+		Subject: "Hi",
+		Content: "Hi",
+	})
+	require.NoError(t, err)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, "<customer@example.org>", server.rcpt)
+	require.Len(t, server.messages, 1)
+	assert.Contains(t, server.messages[0], "To: =?utf-8?q?Ay=C5=9Fe_Y=C4=B1lmaz?= <customer@example.org>\r\n")
+}
+
+func TestSMTPSender_ClassifiesRejections(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		permanent bool
+	}{
+		{"mailbox unavailable", "550 No such user", true},
+		{"greylisted", "451 Try again later", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tt.reply)
+			sender, err := NewSMTPSender(server.config())
+			require.NoError(t, err)
+
+			_, err = sender.SendMessage(context.Background(), &message.WebhookRequest{To: "customer@example.org", Subject: "Hi", Content: "Hi"})
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, message.IsPermanentFailure(err))
+		})
+	}
+}
+
+func TestSMTPSender_RequiresStartTLSWhenConfigured(t *testing.T) {
+	server := newFakeSMTPServer(t, "250 OK")
+	cfg := server.config()
+	cfg.StartTLS = true
+	sender, err := NewSMTPSender(cfg)
+	require.NoError(t, err)
+
+	_, err = sender.SendMessage(context.Background(), &message.WebhookRequest{To: "customer@example.org", Subject: "Hi", Content: "Hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "STARTTLS")
+	assert.False(t, message.IsPermanentFailure(err))
+}
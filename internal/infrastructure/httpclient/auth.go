@@ -0,0 +1,242 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/pkg/logger"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator applies provider credentials to outbound requests
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// refreshableAuthenticator is implemented by authenticators whose credentials
+// can be renewed after the provider rejects them with 401
+type refreshableAuthenticator interface {
+	Authenticator
+	Invalidate()
+}
+
+// NewAuthenticator creates the Authenticator configured for the provider
+func NewAuthenticator(cfg *config.WebhookConfig, client *http.Client) (Authenticator, error) {
+	auth := cfg.Auth
+
+	switch auth.Type {
+	case constants.AuthTypeHeader, "":
+		headerName := auth.HeaderName
+		if headerName == "" {
+			headerName = constants.HeaderAuthKey
+		}
+		return NewHeaderAuthenticator(headerName, cfg.AuthKey), nil
+	case constants.AuthTypeBasic:
+		return NewBasicAuthenticator(auth.Username, auth.Password), nil
+	case constants.AuthTypeBearer:
+		return NewBearerAuthenticator(auth.Token), nil
+	case constants.AuthTypeOAuth2:
+		if auth.TokenURL == "" {
+			return nil, fmt.Errorf("oauth2 token URL cannot be empty")
+		}
+		return NewOAuth2Authenticator(client, auth.TokenURL, auth.ClientID, auth.ClientSecret, auth.Scopes, auth.RefreshBefore), nil
+	case constants.AuthTypeNone:
+		return NewNoopAuthenticator(), nil
+	default:
+		return nil, fmt.Errorf("unsupported webhook auth type: %s", auth.Type)
+	}
+}
+
+// NoopAuthenticator sends requests without credentials
+type NoopAuthenticator struct{}
+
+// NewNoopAuthenticator creates a new NoopAuthenticator
+func NewNoopAuthenticator() Authenticator {
+	return &NoopAuthenticator{}
+}
+
+func (a *NoopAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	return nil
+}
+
+// HeaderAuthenticator sets a static header, e.g. x-ins-auth-key
+type HeaderAuthenticator struct {
+	name  string
+	value string
+}
+
+// NewHeaderAuthenticator creates a new HeaderAuthenticator
+func NewHeaderAuthenticator(name, value string) Authenticator {
+	return &HeaderAuthenticator{name: name, value: value}
+}
+
+func (a *HeaderAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	if a.value != "" {
+		req.Header.Set(a.name, a.value)
+	}
+	return nil
+}
+
+// BasicAuthenticator uses HTTP Basic authentication
+type BasicAuthenticator struct {
+	username string
+	password string
+}
+
+// NewBasicAuthenticator creates a new BasicAuthenticator
+func NewBasicAuthenticator(username, password string) Authenticator {
+	return &BasicAuthenticator{username: username, password: password}
+}
+
+func (a *BasicAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+// BearerAuthenticator sends a static bearer token
+type BearerAuthenticator struct {
+	token string
+}
+
+// NewBearerAuthenticator creates a new BearerAuthenticator
+func NewBearerAuthenticator(token string) Authenticator {
+	return &BearerAuthenticator{token: token}
+}
+
+func (a *BearerAuthenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// OAuth2Authenticator implements the OAuth2 client-credentials grant.
+// Tokens are cached and refreshed refreshBefore ahead of their expiry.
+type OAuth2Authenticator struct {
+	client        *http.Client
+	tokenURL      string
+	clientID      string
+	clientSecret  string
+	scopes        []string
+	refreshBefore time.Duration
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+	now         func() time.Time
+}
+
+// NewOAuth2Authenticator creates a new OAuth2Authenticator
+func NewOAuth2Authenticator(
+	client *http.Client,
+	tokenURL string,
+	clientID string,
+	clientSecret string,
+	scopes []string,
+	refreshBefore time.Duration,
+) *OAuth2Authenticator {
+	return &OAuth2Authenticator{
+		client:        client,
+		tokenURL:      tokenURL,
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		scopes:        scopes,
+		refreshBefore: refreshBefore,
+		now:           time.Now,
+	}
+}
+
+func (a *OAuth2Authenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate drops the cached token so that the next request fetches a new one
+func (a *OAuth2Authenticator) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.accessToken = ""
+	a.expiresAt = time.Time{}
+}
+
+// token returns the cached token or fetches a new one when it is about to expire
+func (a *OAuth2Authenticator) token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken != "" && a.now().Add(a.refreshBefore).Before(a.expiresAt) {
+		return a.accessToken, nil
+	}
+
+	token, expiresIn, err := a.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	a.accessToken = token
+	a.expiresAt = a.now().Add(expiresIn)
+	return a.accessToken, nil
+}
+
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func (a *OAuth2Authenticator) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(a.clientID, a.clientSecret)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		logger.Error("Failed to request OAuth2 token", "error", err, "url", a.tokenURL)
+		return "", 0, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("Unexpected OAuth2 token status code", "status_code", resp.StatusCode, "body", string(body))
+		return "", 0, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("unexpected token status code: %d, body: %s", resp.StatusCode, string(body)),
+		}
+	}
+
+	var tokenResp oauth2TokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", 0, fmt.Errorf("failed to unmarshal token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", 0, fmt.Errorf("access_token is required in token response but was empty")
+	}
+
+	logger.Info("OAuth2 token refreshed", "url", a.tokenURL, "expires_in", tokenResp.ExpiresIn)
+	return tokenResp.AccessToken, time.Duration(tokenResp.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,169 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenServer returns a client-credentials token endpoint issuing token-1, token-2, ...
+func newTokenServer(t *testing.T, expiresIn int, issued *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "sms.send", r.PostForm.Get("scope"))
+
+		n := atomic.AddInt32(issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+}
+
+func newOAuth2Client(providerURL, tokenURL string) *WebhookClient {
+	cfg := testConfig(providerURL)
+	cfg.Webhook.Auth.Type = constants.AuthTypeOAuth2
+	cfg.Webhook.Auth.TokenURL = tokenURL
+	cfg.Webhook.Auth.ClientID = "client"
+	cfg.Webhook.Auth.ClientSecret = "secret"
+	cfg.Webhook.Auth.Scopes = []string{"sms.send"}
+	cfg.Webhook.Auth.RefreshBefore = 30 * time.Second
+	return NewWebhookClient(cfg).(*WebhookClient)
+}
+
+func acceptedHandler(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"abc"}`))
+}
+
+func TestOAuth2Authenticator_CachesToken(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, &issued)
+	defer tokenServer.Close()
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		acceptedHandler(w)
+	}))
+	defer provider.Close()
+
+	client := newOAuth2Client(provider.URL, tokenServer.URL)
+	for i := 0; i < 3; i++ {
+		_, err := client.SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&issued))
+}
+
+func TestOAuth2Authenticator_RefreshesBeforeExpiry(t *testing.T) {
+	var issued int32
+	// Tokens expire within the refresh window, so each request fetches a new one
+	tokenServer := newTokenServer(t, 10, &issued)
+	defer tokenServer.Close()
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptedHandler(w)
+	}))
+	defer provider.Close()
+
+	client := newOAuth2Client(provider.URL, tokenServer.URL)
+	for i := 0; i < 2; i++ {
+		_, err := client.SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&issued))
+}
+
+func TestOAuth2Authenticator_RefreshesOnUnauthorized(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, 3600, &issued)
+	defer tokenServer.Close()
+
+	// The provider revoked token-1 and only accepts token-2
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		acceptedHandler(w)
+	}))
+	defer provider.Close()
+
+	resp, err := newOAuth2Client(provider.URL, tokenServer.URL).
+		SendMessage(budgetContext(context.Background(), 2), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.MessageID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&issued))
+}
+
+func TestOAuth2Authenticator_RefreshStaysWithinDefaultBudget(t *testing.T) {
+	var issued, calls int32
+	tokenServer := newTokenServer(t, 3600, &issued)
+	defer tokenServer.Close()
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer provider.Close()
+
+	// Without a budget in the context only one provider call is allowed
+	_, err := newOAuth2Client(provider.URL, tokenServer.URL).
+		SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestStaticAuthenticators(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   Authenticator
+		assert func(t *testing.T, r *http.Request)
+	}{
+		{
+			name: "header",
+			auth: NewHeaderAuthenticator(constants.HeaderAuthKey, "key"),
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "key", r.Header.Get(constants.HeaderAuthKey))
+			},
+		},
+		{
+			name: "basic",
+			auth: NewBasicAuthenticator("user", "pass"),
+			assert: func(t *testing.T, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "user", username)
+				assert.Equal(t, "pass", password)
+			},
+		},
+		{
+			name: "bearer",
+			auth: NewBearerAuthenticator("token"),
+			assert: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			require.NoError(t, tt.auth.Authenticate(context.Background(), req))
+			tt.assert(t, req)
+		})
+	}
+}
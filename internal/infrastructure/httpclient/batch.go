@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"net/http"
+)
+
+// batchPermanentCodes are per-recipient error codes that will never succeed on retry.
+// Unknown codes are treated as transient and left to the retry budget.
+var batchPermanentCodes = map[string]bool{
+	"INVALID_NUMBER":   true,
+	"INVALID_CONTENT":  true,
+	"BLACKLISTED":      true,
+	"OPTED_OUT":        true,
+	"UNAUTHORIZED":     true,
+	"INSUFFICIENT_BAL": true,
+}
+
+// BatchClient sends messages to batch-style APIs that accept many recipients in one call
+type BatchClient struct {
+	transport *transport
+	url       string
+	from      string
+}
+
+// BatchResult is the outcome for a single request of a batch
+type BatchResult struct {
+	Response *message.WebhookResponse
+	Err      error
+}
+
+type batchRequest struct {
+	From     string                    `json:"from,omitempty"`
+	Messages []*message.WebhookRequest `json:"messages"`
+}
+
+type batchResponse struct {
+	Results []struct {
+		To           string `json:"to"`
+		MessageID    string `json:"messageId"`
+		Status       string `json:"status"`
+		ErrorCode    string `json:"errorCode"`
+		ErrorMessage string `json:"errorMessage"`
+	} `json:"results"`
+}
+
+// NewBatchClient creates a new BatchClient
+func NewBatchClient(cfg *config.WebhookConfig) (*BatchClient, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook URL cannot be empty")
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchClient{
+		transport: transport,
+		url:       cfg.URL,
+		from:      cfg.SenderID,
+	}, nil
+}
+
+// SendMessage sends a batch containing a single message, retrying transient
+// failures within the attempt budget carried by ctx
+func (c *BatchClient) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	return c.transport.retry(ctx, func(ctx context.Context, budget *message.AttemptBudget) (*message.WebhookResponse, error) {
+		results, err := c.sendBatch(ctx, budget, []*message.WebhookRequest{req})
+		if err != nil {
+			return nil, err
+		}
+		return results[0].Response, results[0].Err
+	})
+}
+
+// SendBatch sends all requests in one call. The returned results are in the
+// same order as reqs; a non-nil error means the whole batch failed.
+func (c *BatchClient) SendBatch(ctx context.Context, reqs []*message.WebhookRequest) ([]BatchResult, error) {
+	return c.sendBatch(ctx, message.AttemptBudgetFromContext(ctx), reqs)
+}
+
+func (c *BatchClient) sendBatch(ctx context.Context, budget *message.AttemptBudget, reqs []*message.WebhookRequest) ([]BatchResult, error) {
+	payload, err := json.Marshal(&batchRequest{From: c.from, Messages: reqs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.transport.do(ctx, budget, http.MethodPost, c.url, "application/json", payload)
+	if err != nil {
+		return nil, transportError(err)
+	}
+	if err := c.transport.throttled(resp); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, classifyStatus(resp.StatusCode, resp.Body)
+	}
+
+	var body batchResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, &message.ErrDelivery{Err: fmt.Errorf("failed to unmarshal response: %w", err)}
+	}
+	if len(body.Results) != len(reqs) {
+		return nil, &message.ErrDelivery{
+			Err: fmt.Errorf("provider returned %d results for %d messages", len(body.Results), len(reqs)),
+		}
+	}
+
+	results := make([]BatchResult, len(reqs))
+	for i, item := range body.Results {
+		if item.ErrorCode != "" {
+			logger.Error("Batch provider rejected recipient",
+				"to", item.To,
+				"error_code", item.ErrorCode,
+				"error", item.ErrorMessage,
+			)
+			results[i].Err = &message.ErrDelivery{
+				Permanent: batchPermanentCodes[item.ErrorCode],
+				Code:      item.ErrorCode,
+				Err:       fmt.Errorf("provider error: %s", item.ErrorMessage),
+			}
+			continue
+		}
+
+		if item.MessageID == "" {
+			results[i].Err = &message.ErrDelivery{Err: errors.New("messageId is required in provider response but was empty")}
+			continue
+		}
+
+		results[i].Response = &message.WebhookResponse{
+			Message:   item.Status,
+			MessageID: item.MessageID,
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeBatchProvider emulates a batch endpoint accepting many recipients per call.
+// Recipients listed in errorCodes are rejected with that code.
+func newFakeBatchProvider(t *testing.T, errorCodes map[string]string, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		var req batchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		results := make([]string, 0, len(req.Messages))
+		for i, msg := range req.Messages {
+			if code, ok := errorCodes[msg.To]; ok {
+				results = append(results, fmt.Sprintf(`{"to":%q,"status":"rejected","errorCode":%q,"errorMessage":"rejected"}`, msg.To, code))
+				continue
+			}
+			results = append(results, fmt.Sprintf(`{"to":%q,"status":"accepted","messageId":"batch-%d"}`, msg.To, i))
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, `{"results":[%s]}`, strings.Join(results, ","))
+	}))
+}
+
+func TestBatchClient_SendBatch(t *testing.T) {
+	var calls int32
+	server := newFakeBatchProvider(t, map[string]string{
+		"+905552222222": "OPTED_OUT",
+		"+905553333333": "TEMPORARY_FAILURE",
+	}, &calls)
+	defer server.Close()
+
+	client, err := NewBatchClient(&testConfig(server.URL).Webhook)
+	require.NoError(t, err)
+
+	results, err := client.SendBatch(context.Background(), []*message.WebhookRequest{
+		{To: "+905551111111", Content: "hi"},
+		{To: "+905552222222", Content: "hi"},
+		{To: "+905553333333", Content: "hi"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "batch-0", results[0].Response.MessageID)
+	assert.True(t, message.IsPermanentFailure(results[1].Err))
+	assert.Error(t, results[2].Err)
+	assert.False(t, message.IsPermanentFailure(results[2].Err))
+}
+
+func TestBatchClient_SendMessage(t *testing.T) {
+	var calls int32
+	server := newFakeBatchProvider(t, nil, &calls)
+	defer server.Close()
+
+	client, err := NewBatchClient(&testConfig(server.URL).Webhook)
+	require.NoError(t, err)
+
+	resp, err := client.SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "batch-0", resp.MessageID)
+}
+
+func TestBatchClient_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := NewBatchClient(&testConfig(server.URL).Webhook)
+	require.NoError(t, err)
+
+	_, err = client.SendBatch(context.Background(), []*message.WebhookRequest{{To: "+905551111111", Content: "hi"}})
+	require.Error(t, err)
+	assert.False(t, message.IsPermanentFailure(err))
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// PayloadMapper translates between message.WebhookRequest/WebhookResponse and
+// the provider specific request and response bodies
+type PayloadMapper struct {
+	requestTemplate *template.Template
+	contentType     string
+	messageIDPath   string
+	statusPath      string
+}
+
+// NewPayloadMapper creates a PayloadMapper from the provider configuration.
+// Without a request template the request is marshalled as {"to", "content"}
+// and the response is read as {"message", "messageId"}.
+func NewPayloadMapper(cfg *config.WebhookConfig) (*PayloadMapper, error) {
+	mapper := &PayloadMapper{
+		contentType:   cfg.ContentType,
+		messageIDPath: cfg.ResponseMessageIDPath,
+		statusPath:    cfg.ResponseStatusPath,
+	}
+
+	if mapper.contentType == "" {
+		mapper.contentType = "application/json"
+	}
+	if mapper.messageIDPath == "" {
+		mapper.messageIDPath = "messageId"
+	}
+	if mapper.statusPath == "" {
+		mapper.statusPath = "message"
+	}
+
+	if cfg.RequestTemplate != "" {
+		tmpl, err := template.New("webhook_request").
+			Funcs(template.FuncMap{"json": toJSON}).
+			Option("missingkey=error").
+			Parse(cfg.RequestTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse webhook request template: %w", err)
+		}
+		mapper.requestTemplate = tmpl
+	}
+
+	return mapper, nil
+}
+
+// ContentType returns the Content-Type header for encoded requests
+func (m *PayloadMapper) ContentType() string {
+	return m.contentType
+}
+
+// EncodeRequest renders the provider request body
+func (m *PayloadMapper) EncodeRequest(req *message.WebhookRequest) ([]byte, error) {
+	if m.requestTemplate == nil {
+		return json.Marshal(req)
+	}
+
+	var buf bytes.Buffer
+	if err := m.requestTemplate.Execute(&buf, req); err != nil {
+		return nil, fmt.Errorf("failed to render request template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeResponse extracts the provider message ID and status from a JSON response body
+func (m *PayloadMapper) DecodeResponse(body []byte) (*message.WebhookResponse, error) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	resp := &message.WebhookResponse{}
+	if value, ok := lookupJSONPath(data, m.messageIDPath); ok {
+		resp.MessageID = value
+	}
+	if value, ok := lookupJSONPath(data, m.statusPath); ok {
+		resp.Message = value
+	}
+
+	return resp, nil
+}
+
+// lookupJSONPath resolves a dot separated path such as "messages.0.message-id"
+// against decoded JSON and returns the value as a string
+func lookupJSONPath(data interface{}, path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+
+	current := data
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return "", false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			current = node[index]
+		default:
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case nil:
+		return "", false
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(encoded), true
+	}
+}
+
+// toJSON is exposed to request templates as "json" so values are escaped safely
+func toJSON(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
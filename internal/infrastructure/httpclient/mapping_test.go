@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadMapper_DefaultShape(t *testing.T) {
+	mapper, err := NewPayloadMapper(&config.WebhookConfig{})
+	require.NoError(t, err)
+
+	body, err := mapper.EncodeRequest(&message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"to":"+905551111111","content":"hi"}`, string(body))
+
+	resp, err := mapper.DecodeResponse([]byte(`{"message":"Accepted","messageId":"abc"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.MessageID)
+	assert.Equal(t, "Accepted", resp.Message)
+}
+
+func TestPayloadMapper_Template(t *testing.T) {
+	mapper, err := NewPayloadMapper(&config.WebhookConfig{
+		RequestTemplate: `{"recipient":{"msisdn":{{json .To}}},"text":{{json .Content}}}`,
+	})
+	require.NoError(t, err)
+
+	body, err := mapper.EncodeRequest(&message.WebhookRequest{To: "+905551111111", Content: `say "hi"`})
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, `say "hi"`, decoded["text"])
+}
+
+func TestPayloadMapper_InvalidTemplate(t *testing.T) {
+	_, err := NewPayloadMapper(&config.WebhookConfig{RequestTemplate: `{{.To`})
+	assert.Error(t, err)
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"messages":[{"message-id":"id-1","status":0}],"ok":true}`), &data))
+
+	value, ok := lookupJSONPath(data, "messages.0.message-id")
+	assert.True(t, ok)
+	assert.Equal(t, "id-1", value)
+
+	value, ok = lookupJSONPath(data, "messages.0.status")
+	assert.True(t, ok)
+	assert.Equal(t, "0", value)
+
+	_, ok = lookupJSONPath(data, "messages.1.message-id")
+	assert.False(t, ok)
+	_, ok = lookupJSONPath(data, "missing")
+	assert.False(t, ok)
+}
+
+func TestWebhookClient_CustomMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "To=%2B905551111111&Body=hi+there", string(body))
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":{"id":"prov-1","state":"queued"}}`))
+	}))
+	defer server.Close()
+
+	cfg := testConfig(server.URL)
+	cfg.Webhook.Method = http.MethodPut
+	cfg.Webhook.SuccessStatusCodes = []int{http.StatusOK, http.StatusCreated}
+	cfg.Webhook.ContentType = "application/x-www-form-urlencoded"
+	cfg.Webhook.RequestTemplate = `To={{urlquery .To}}&Body={{urlquery .Content}}`
+	cfg.Webhook.ResponseMessageIDPath = "data.id"
+	cfg.Webhook.ResponseStatusPath = "data.state"
+
+	resp, err := NewWebhookClient(cfg).SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi there"})
+	require.NoError(t, err)
+	assert.Equal(t, "prov-1", resp.MessageID)
+	assert.Equal(t, "queued", resp.Message)
+}
+
+func TestWebhookClient_RejectsUnlistedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"abc"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewWebhookClient(testConfig(server.URL)).SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"net/http"
+)
+
+// NewProviderClient creates the message.WebhookClient for the configured provider
+func NewProviderClient(cfg *config.Config) (message.WebhookClient, error) {
+	switch cfg.Webhook.Provider {
+	case constants.ProviderGeneric, "":
+		return NewWebhookClient(cfg), nil
+	case constants.ProviderTwilio:
+		return NewTwilioClient(&cfg.Webhook)
+	case constants.ProviderVonage:
+		return NewVonageClient(&cfg.Webhook)
+	case constants.ProviderBatch:
+		return NewBatchClient(&cfg.Webhook)
+	default:
+		return nil, fmt.Errorf("unsupported webhook provider: %s", cfg.Webhook.Provider)
+	}
+}
+
+// isPermanentStatus classifies HTTP status codes that retrying cannot fix.
+// Timeouts, throttling and server errors are transient, other 4xx are permanent.
+func isPermanentStatus(statusCode int) bool {
+	switch {
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return false
+	case statusCode >= 500:
+		return false
+	default:
+		return statusCode >= 400
+	}
+}
+
+// classifyStatus wraps an unexpected HTTP status in a message.ErrDelivery
+func classifyStatus(statusCode int, body []byte) error {
+	return &message.ErrDelivery{
+		Permanent: isPermanentStatus(statusCode),
+		Code:      fmt.Sprintf("http_%d", statusCode),
+		Err: &HTTPError{
+			StatusCode: statusCode,
+			Message:    fmt.Sprintf("unexpected status code: %d, body: %s", statusCode, string(body)),
+		},
+	}
+}
+
+// transportError wraps transport failures as transient delivery errors,
+// throttling signals are passed through unchanged
+func transportError(err error) error {
+	if message.IsThrottled(err) {
+		return err
+	}
+	return &message.ErrDelivery{Err: err}
+}
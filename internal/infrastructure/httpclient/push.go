@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"strings"
+)
+
+// PushClient sends push notifications to a generic push webhook, such as a
+// relay in front of FCM or APNs
+type PushClient struct {
+	transport    *transport
+	url          string
+	successCodes map[int]bool
+	mapper       *PayloadMapper
+}
+
+type pushRequest struct {
+	Token string `json:"token"`
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body"`
+	Image string `json:"image,omitempty"` // URL of the first attached image
+}
+
+// NewPushClient creates a new PushClient
+func NewPushClient(cfg *config.WebhookConfig) (*PushClient, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("push webhook URL cannot be empty")
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper, err := NewPayloadMapper(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	successCodes := make(map[int]bool, len(cfg.SuccessStatusCodes))
+	for _, code := range cfg.SuccessStatusCodes {
+		successCodes[code] = true
+	}
+	if len(successCodes) == 0 {
+		successCodes[http.StatusOK] = true
+	}
+
+	return &PushClient{
+		transport:    transport,
+		url:          cfg.URL,
+		successCodes: successCodes,
+		mapper:       mapper,
+	}, nil
+}
+
+// SendMessage sends the notification, retrying transient failures within the attempt budget carried by ctx
+func (c *PushClient) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	return c.transport.retry(ctx, func(ctx context.Context, budget *message.AttemptBudget) (*message.WebhookResponse, error) {
+		return c.send(ctx, budget, req)
+	})
+}
+
+func (c *PushClient) send(ctx context.Context, budget *message.AttemptBudget, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	payload, err := json.Marshal(&pushRequest{
+		Token: req.To,
+		Title: req.Subject,
+		Body:  req.Content,
+		Image: firstImage(req.Media),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.transport.do(ctx, budget, http.MethodPost, c.url, "application/json", payload)
+	if err != nil {
+		return nil, transportError(err)
+	}
+	if err := c.transport.throttled(resp); err != nil {
+		return nil, err
+	}
+
+	if !c.successCodes[resp.StatusCode] {
+		return nil, classifyStatus(resp.StatusCode, resp.Body)
+	}
+
+	result, err := c.mapper.DecodeResponse(resp.Body)
+	if err != nil {
+		return nil, &message.ErrDelivery{Err: err}
+	}
+	if result.MessageID == "" {
+		return nil, &message.ErrDelivery{Err: errors.New("notification id is required in push response but was empty")}
+	}
+
+	return result, nil
+}
+
+func firstImage(media []message.WebhookMedia) string {
+	for _, m := range media {
+		if strings.HasPrefix(m.ContentType, "image/") {
+			return m.URL
+		}
+	}
+	return ""
+}
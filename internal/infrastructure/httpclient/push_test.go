@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pushConfig(url string) *config.WebhookConfig {
+	return &config.WebhookConfig{
+		Provider:              constants.ChannelPush,
+		URL:                   url,
+		AuthKey:               "push-key",
+		Timeout:               5 * time.Second,
+		SuccessStatusCodes:    []int{http.StatusOK, http.StatusAccepted},
+		ResponseMessageIDPath: "id",
+		Auth:                  config.WebhookAuthConfig{Type: constants.AuthTypeHeader, HeaderName: constants.HeaderAuthKey},
+	}
+}
+
+func TestPushClient_SendMessage(t *testing.T) {
+	var received pushRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "push-key", r.Header.Get(constants.HeaderAuthKey))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"notif-1"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewPushClient(pushConfig(server.URL))
+	require.NoError(t, err)
+
+	resp, err := client.SendMessage(context.Background(), &message.WebhookRequest{To: "device-token", Subject: "Order shipped", Content: "Your order is on its way"})
+	require.NoError(t, err)
+	assert.Equal(t, "notif-1", resp.MessageID)
+	assert.Equal(t, pushRequest{Token: "device-token", Title: "Order shipped", Body: "Your order is on its way"}, received)
+}
+
+func TestPushClient_UnregisteredTokenIsPermanent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	client, err := NewPushClient(pushConfig(server.URL))
+	require.NoError(t, err)
+
+	_, err = client.SendMessage(budgetContext(context.Background(), 3), &message.WebhookRequest{To: "stale-token", Content: "hi"})
+	require.Error(t, err)
+	assert.True(t, message.IsPermanentFailure(err))
+}
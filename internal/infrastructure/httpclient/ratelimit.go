@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces outbound requests and backs off when the provider throttles us
+type RateLimiter interface {
+	// Wait blocks until the next request may be sent
+	Wait(ctx context.Context) error
+	// Throttle pauses all requests for d, e.g. after a Retry-After response
+	Throttle(d time.Duration)
+}
+
+// IntervalLimiter spaces requests evenly and honours provider pauses
+type IntervalLimiter struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	next        time.Time
+	pausedUntil time.Time
+	now         func() time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perSecond requests per second,
+// zero or less only honours Throttle pauses
+func NewRateLimiter(perSecond int) RateLimiter {
+	var interval time.Duration
+	if perSecond > 0 {
+		interval = time.Second / time.Duration(perSecond)
+	}
+	return &IntervalLimiter{
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+func (l *IntervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	start := now
+	if l.next.After(start) {
+		start = l.next
+	}
+	if l.pausedUntil.After(start) {
+		start = l.pausedUntil
+	}
+
+	// Do not block past the caller's deadline, report the pause instead
+	if deadline, ok := ctx.Deadline(); ok && start.After(deadline) {
+		l.mu.Unlock()
+		return &message.ErrThrottled{
+			RetryAfter: start.Sub(now),
+			Err:        fmt.Errorf("provider pause of %s exceeds deadline", start.Sub(now).Round(time.Millisecond)),
+		}
+	}
+
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := start.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *IntervalLimiter) Throttle(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := l.now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// isThrottleStatus reports whether the provider asked us to slow down
+func isThrottleStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP-date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// fitsDeadline reports whether waiting d still leaves room before the context deadline
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+	return time.Now().Add(d).Before(deadline)
+}
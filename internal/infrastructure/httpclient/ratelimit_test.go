@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, ok = parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+}
+
+func TestIntervalLimiter_PauseExceedingDeadline(t *testing.T) {
+	limiter := NewRateLimiter(0)
+	limiter.Throttle(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	assert.True(t, message.IsThrottled(err))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestIntervalLimiter_Paces(t *testing.T) {
+	limiter := NewRateLimiter(20) // one request every 50ms
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestWebhookClient_HonorsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		acceptedHandler(w)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := NewWebhookClient(testConfig(server.URL)).
+		SendMessage(budgetContext(context.Background(), 3), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.MessageID)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookClient_ThrottledBeyondDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewWebhookClient(testConfig(server.URL)).
+		SendMessage(budgetContext(ctx, 4), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.Error(t, err)
+	assert.True(t, message.IsThrottled(err))
+	assert.False(t, message.IsPermanentFailure(err))
+	assert.Less(t, time.Since(start), time.Second)
+}
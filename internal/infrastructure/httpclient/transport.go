@@ -0,0 +1,248 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"insider-case/internal/pkg/signature"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// maxRecordedBodyBytes limits how much of a response body is kept per attempt
+const maxRecordedBodyBytes = 2048
+
+// transport performs authenticated and signed HTTP calls to a provider.
+// It is shared by the generic WebhookClient and the provider adapters.
+type transport struct {
+	provider        string
+	client          *http.Client
+	authenticator   Authenticator
+	limiter         RateLimiter
+	retryDelay      time.Duration
+	signer          *signature.Signer
+	signatureHeader string
+	timestampHeader string
+}
+
+// providerResponse is the raw result of a provider call
+type providerResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	RetryAfter time.Duration // Set for 429/503 responses
+}
+
+func newTransport(cfg *config.WebhookConfig) (*transport, error) {
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+	}
+
+	authenticator, err := NewAuthenticator(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := cfg.Provider
+	if provider == "" {
+		provider = constants.ProviderGeneric
+	}
+
+	return &transport{
+		provider:        provider,
+		client:          client,
+		authenticator:   authenticator,
+		limiter:         NewRateLimiter(cfg.RateLimit),
+		retryDelay:      cfg.RetryDelay,
+		signer:          signature.NewSigner(cfg.SigningSecret, cfg.SigningSecretPrevious),
+		signatureHeader: cfg.SignatureHeader,
+		timestampHeader: cfg.TimestampHeader,
+	}, nil
+}
+
+// retry calls send until it succeeds, fails permanently or the attempt budget
+// of the current run is spent. Every call gets the same budget, so it holds
+// across do calls even when ctx carries none. Throttled calls wait for
+// Retry-After as long as that fits within the context deadline; everything
+// else waits retryDelay.
+func (t *transport) retry(ctx context.Context, send func(ctx context.Context, budget *message.AttemptBudget) (*message.WebhookResponse, error)) (*message.WebhookResponse, error) {
+	budget := message.AttemptBudgetFromContext(ctx)
+
+	for {
+		calls := budget.Calls()
+
+		resp, err := send(ctx, budget)
+		if err == nil {
+			return resp, nil
+		}
+
+		// Failures that happened before reaching the provider are not retried here
+		if budget.Calls() == calls || message.IsPermanentFailure(err) || !budget.Allow() {
+			return nil, err
+		}
+
+		delay := t.retryDelay
+		var throttledErr *message.ErrThrottled
+		if errors.As(err, &throttledErr) {
+			if !fitsDeadline(ctx, throttledErr.RetryAfter) {
+				logger.Warn("Provider throttled, giving up for this run", "retry_after", throttledErr.RetryAfter)
+				return nil, err
+			}
+			delay = throttledErr.RetryAfter
+		}
+
+		logger.Warn("Retrying provider request", "attempts", budget.Calls(), "delay", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// do sends the payload and reads the response body. When the provider answers
+// 401 and the credentials are refreshable, they are refreshed and the call is retried once.
+// Throttling responses pause the rate limiter for the Retry-After duration.
+// Every call, the refresh retry included, is made within budget.
+func (t *transport) do(ctx context.Context, budget *message.AttemptBudget, method, url, contentType string, payload []byte) (*providerResponse, error) {
+	resp, err := t.attempt(ctx, budget, method, url, contentType, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Credentials may have been revoked or expired early, refresh once and retry
+	if resp.StatusCode == http.StatusUnauthorized && budget.Allow() {
+		if refresher, ok := t.authenticator.(refreshableAuthenticator); ok {
+			logger.Warn("Provider returned 401, refreshing credentials", "url", url)
+			refresher.Invalidate()
+
+			resp, err = t.attempt(ctx, budget, method, url, contentType, payload)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if isThrottleStatus(resp.StatusCode) {
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			retryAfter = t.retryDelay
+		}
+		resp.RetryAfter = retryAfter
+
+		logger.Warn("Provider throttled request",
+			"status_code", resp.StatusCode,
+			"retry_after", retryAfter,
+			"url", url,
+		)
+		t.limiter.Throttle(retryAfter)
+	}
+
+	return resp, nil
+}
+
+// attempt makes one provider call and records it in the attempt budget
+func (t *transport) attempt(ctx context.Context, budget *message.AttemptBudget, method, url, contentType string, payload []byte) (*providerResponse, error) {
+	if !budget.Allow() {
+		return nil, message.ErrAttemptBudgetExhausted
+	}
+
+	if err := t.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	attempt := budget.Start()
+	attempt.Provider = t.provider
+	resp, err := t.send(ctx, method, url, contentType, payload)
+	attempt.Latency = time.Since(attempt.RequestedAt)
+	attempt.Err = err
+	if resp != nil {
+		attempt.StatusCode = resp.StatusCode
+		attempt.ResponseBody = truncateBody(resp.Body)
+		attempt.Throttled = isThrottleStatus(resp.StatusCode)
+	}
+
+	if recordErr := budget.Record(ctx, attempt); recordErr != nil {
+		logger.Warn("Failed to record delivery attempt", "attempt", attempt.Number, "error", recordErr)
+	}
+
+	return resp, err
+}
+
+// truncateBody shortens a response body to maxRecordedBodyBytes for storage,
+// cutting before the rune that crosses the limit so the result stays valid UTF-8
+func truncateBody(body []byte) string {
+	if len(body) <= maxRecordedBodyBytes {
+		return string(body)
+	}
+	cut := maxRecordedBodyBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]) + "...(truncated)"
+}
+
+// throttled converts a 429/503 response into a message.ErrThrottled, nil otherwise
+func (t *transport) throttled(resp *providerResponse) error {
+	if !isThrottleStatus(resp.StatusCode) {
+		return nil
+	}
+	return &message.ErrThrottled{
+		RetryAfter: resp.RetryAfter,
+		Err: &HTTPError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("unexpected status code: %d, body: %s", resp.StatusCode, string(resp.Body)),
+		},
+	}
+}
+
+// send builds, authenticates, signs and sends a single HTTP request
+func (t *transport) send(ctx context.Context, method, url, contentType string, payload []byte) (*providerResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
+	if err != nil {
+		logger.Error("Failed to create provider request", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", contentType)
+
+	if err := t.authenticator.Authenticate(ctx, httpReq); err != nil {
+		logger.Error("Failed to authenticate provider request", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to authenticate request: %w", err)
+	}
+
+	if t.signer.Enabled() {
+		timestamp, sig := t.signer.Sign(payload, time.Now())
+		httpReq.Header.Set(t.timestampHeader, timestamp)
+		httpReq.Header.Set(t.signatureHeader, sig)
+	}
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		logger.Error("Failed to send provider request", "error", err, "url", url)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read provider response", "error", err, "status_code", resp.StatusCode)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return &providerResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// twilioPermanentCodes are Twilio-style error codes that will never succeed on retry
+var twilioPermanentCodes = map[int]bool{
+	20003: true, // Authentication failed
+	21211: true, // Invalid 'To' phone number
+	21408: true, // Permission to send to region not enabled
+	21606: true, // 'From' number is not a valid sender
+	21610: true, // Recipient unsubscribed (STOP)
+	21612: true, // Cannot route to this number
+	21614: true, // 'To' number is not a mobile number
+	21617: true, // Body exceeds maximum length
+}
+
+// twilioTransientCodes are Twilio-style error codes that may succeed later
+var twilioTransientCodes = map[int]bool{
+	20429: true, // Too many requests
+	20500: true, // Internal server error
+	20503: true, // Service unavailable
+	30001: true, // Queue overflow
+}
+
+// TwilioClient sends messages to form-encoded Twilio-style APIs.
+// Credentials are supplied through the configured Authenticator, usually basic auth.
+type TwilioClient struct {
+	transport *transport
+	url       string
+	from      string
+}
+
+type twilioResponse struct {
+	SID     string `json:"sid"`
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewTwilioClient creates a new TwilioClient
+func NewTwilioClient(cfg *config.WebhookConfig) (*TwilioClient, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook URL cannot be empty")
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwilioClient{
+		transport: transport,
+		url:       cfg.URL,
+		from:      cfg.SenderID,
+	}, nil
+}
+
+// SendMessage sends the message, retrying transient failures within the attempt budget carried by ctx
+func (c *TwilioClient) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	return c.transport.retry(ctx, func(ctx context.Context, budget *message.AttemptBudget) (*message.WebhookResponse, error) {
+		return c.send(ctx, budget, req)
+	})
+}
+
+func (c *TwilioClient) send(ctx context.Context, budget *message.AttemptBudget, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	form := url.Values{}
+	form.Set("To", req.To)
+	form.Set("From", c.from)
+	form.Set("Body", req.Content)
+	for _, media := range req.Media {
+		form.Add("MediaUrl", media.URL)
+	}
+
+	resp, err := c.transport.do(ctx, budget, http.MethodPost, c.url, "application/x-www-form-urlencoded", []byte(form.Encode()))
+	if err != nil {
+		return nil, transportError(err)
+	}
+	if err := c.transport.throttled(resp); err != nil {
+		return nil, err
+	}
+
+	var body twilioResponse
+	_ = json.Unmarshal(resp.Body, &body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		logger.Error("Twilio-style provider rejected message",
+			"status_code", resp.StatusCode,
+			"error_code", body.Code,
+			"error", body.Message,
+		)
+		if body.Code == 0 {
+			return nil, classifyStatus(resp.StatusCode, resp.Body)
+		}
+		return nil, &message.ErrDelivery{
+			Permanent: classifyTwilioCode(body.Code, resp.StatusCode),
+			Code:      strconv.Itoa(body.Code),
+			Err:       fmt.Errorf("provider error: %s", body.Message),
+		}
+	}
+
+	if body.SID == "" {
+		return nil, &message.ErrDelivery{Err: errors.New("sid is required in provider response but was empty")}
+	}
+
+	return &message.WebhookResponse{
+		Message:   body.Status,
+		MessageID: body.SID,
+	}, nil
+}
+
+// classifyTwilioCode reports whether a Twilio-style error code is permanent,
+// unknown codes fall back to the HTTP status classification
+func classifyTwilioCode(code, statusCode int) bool {
+	if twilioPermanentCodes[code] {
+		return true
+	}
+	if twilioTransientCodes[code] {
+		return false
+	}
+	return isPermanentStatus(statusCode)
+}
@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeTwilio emulates a Twilio-style Messages endpoint. Numbers listed in
+// failures are rejected with the given HTTP status and error code.
+func newFakeTwilio(t *testing.T, failures map[string][2]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "AC123" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":20003,"message":"Authenticate","status":401}`))
+			return
+		}
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "+905550000000", r.PostForm.Get("From"))
+
+		to := r.PostForm.Get("To")
+		if failure, ok := failures[to]; ok {
+			w.WriteHeader(failure[0])
+			_, _ = fmt.Fprintf(w, `{"code":%d,"message":"rejected","status":%d}`, failure[1], failure[0])
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+}
+
+func newTestTwilioClient(t *testing.T, url, password string) *TwilioClient {
+	cfg := testConfig(url).Webhook
+	cfg.SenderID = "+905550000000"
+	cfg.Auth.Type = constants.AuthTypeBasic
+	cfg.Auth.Username = "AC123"
+	cfg.Auth.Password = password
+
+	client, err := NewTwilioClient(&cfg)
+	require.NoError(t, err)
+	return client
+}
+
+func TestTwilioClient_SendMessage(t *testing.T) {
+	server := newFakeTwilio(t, nil)
+	defer server.Close()
+
+	resp, err := newTestTwilioClient(t, server.URL, "token").
+		SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", resp.MessageID)
+	assert.Equal(t, "queued", resp.Message)
+}
+
+func TestTwilioClient_ErrorClassification(t *testing.T) {
+	server := newFakeTwilio(t, map[string][2]int{
+		"+905551111111": {http.StatusBadRequest, 21211},         // invalid number
+		"+905552222222": {http.StatusTooManyRequests, 20429},    // throttled
+		"+905553333333": {http.StatusServiceUnavailable, 99999}, // unknown code, 5xx
+	})
+	defer server.Close()
+
+	client := newTestTwilioClient(t, server.URL, "token")
+	tests := []struct {
+		to        string
+		permanent bool
+	}{
+		{"+905551111111", true},
+		{"+905552222222", false},
+		{"+905553333333", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.to, func(t *testing.T) {
+			_, err := client.SendMessage(context.Background(), &message.WebhookRequest{To: tt.to, Content: "hi"})
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, message.IsPermanentFailure(err))
+		})
+	}
+
+	_, err := newTestTwilioClient(t, server.URL, "wrong").
+		SendMessage(context.Background(), &message.WebhookRequest{To: "+905554444444", Content: "hi"})
+	assert.True(t, message.IsPermanentFailure(err))
+}
+
+func TestTwilioClient_SendsMediaURLs(t *testing.T) {
+	var mediaURLs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		mediaURLs = r.PostForm["MediaUrl"]
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestTwilioClient(t, server.URL, "token").SendMessage(context.Background(), &message.WebhookRequest{
+		To:      "+905551111111",
+		Content: "hi",
+		Media: []message.WebhookMedia{
+			{URL: "https://cdn.example.com/a.png", ContentType: "image/png"},
+			{URL: "https://cdn.example.com/b.pdf", ContentType: "application/pdf"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.pdf"}, mediaURLs)
+}
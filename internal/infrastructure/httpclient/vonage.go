@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"net/http"
+	"strings"
+)
+
+// vonageTransientStatuses are Vonage-style per-message statuses that may succeed later.
+// Every other non-zero status (invalid number, barred, bad credentials...) is permanent.
+var vonageTransientStatuses = map[string]bool{
+	"1":  true, // Throttled
+	"5":  true, // Internal error
+	"10": true, // Too many existing binds
+}
+
+// VonageClient sends messages to JSON Vonage-style APIs that take
+// credentials in the body and report a status per message
+type VonageClient struct {
+	transport *transport
+	url       string
+	from      string
+	apiKey    string
+	apiSecret string
+}
+
+type vonageRequest struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Text      string `json:"text"`
+}
+
+type vonageResponse struct {
+	MessageCount string `json:"message-count"`
+	Messages     []struct {
+		To        string `json:"to"`
+		MessageID string `json:"message-id"`
+		Status    string `json:"status"`
+		ErrorText string `json:"error-text"`
+	} `json:"messages"`
+}
+
+// NewVonageClient creates a new VonageClient
+func NewVonageClient(cfg *config.WebhookConfig) (*VonageClient, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook URL cannot be empty")
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VonageClient{
+		transport: transport,
+		url:       cfg.URL,
+		from:      cfg.SenderID,
+		apiKey:    cfg.APIKey,
+		apiSecret: cfg.APISecret,
+	}, nil
+}
+
+// SendMessage sends the message, retrying transient failures within the attempt budget carried by ctx
+func (c *VonageClient) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	return c.transport.retry(ctx, func(ctx context.Context, budget *message.AttemptBudget) (*message.WebhookResponse, error) {
+		return c.send(ctx, budget, req)
+	})
+}
+
+func (c *VonageClient) send(ctx context.Context, budget *message.AttemptBudget, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	payload, err := json.Marshal(&vonageRequest{
+		APIKey:    c.apiKey,
+		APISecret: c.apiSecret,
+		From:      c.from,
+		// Vonage-style APIs expect the number without the leading '+'
+		To:   strings.TrimPrefix(req.To, "+"),
+		Text: req.Content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.transport.do(ctx, budget, http.MethodPost, c.url, "application/json", payload)
+	if err != nil {
+		return nil, transportError(err)
+	}
+	if err := c.transport.throttled(resp); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyStatus(resp.StatusCode, resp.Body)
+	}
+
+	var body vonageResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, &message.ErrDelivery{Err: fmt.Errorf("failed to unmarshal response: %w", err)}
+	}
+	if len(body.Messages) == 0 {
+		return nil, &message.ErrDelivery{Err: errors.New("provider response contains no messages")}
+	}
+
+	result := body.Messages[0]
+	if result.Status != "0" {
+		logger.Error("Vonage-style provider rejected message",
+			"status", result.Status,
+			"error", result.ErrorText,
+		)
+		return nil, &message.ErrDelivery{
+			Permanent: !vonageTransientStatuses[result.Status],
+			Code:      result.Status,
+			Err:       fmt.Errorf("provider error: %s", result.ErrorText),
+		}
+	}
+
+	if result.MessageID == "" {
+		return nil, &message.ErrDelivery{Err: errors.New("message-id is required in provider response but was empty")}
+	}
+
+	return &message.WebhookResponse{
+		Message:   result.Status,
+		MessageID: result.MessageID,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"insider-case/internal/domain/message"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeVonage emulates a Vonage-style SMS endpoint. It always answers 200 and
+// reports per-message statuses; numbers listed in statuses get that status.
+func newFakeVonage(t *testing.T, statuses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req vonageRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		status := "0"
+		if req.APIKey != "key" || req.APISecret != "secret" {
+			status = "4"
+		} else if s, ok := statuses[req.To]; ok {
+			status = s
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if status == "0" {
+			_, _ = fmt.Fprintf(w, `{"message-count":"1","messages":[{"to":%q,"message-id":"vonage-1","status":"0"}]}`, req.To)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"message-count":"1","messages":[{"to":%q,"status":%q,"error-text":"rejected"}]}`, req.To, status)
+	}))
+}
+
+func newTestVonageClient(t *testing.T, url, secret string) *VonageClient {
+	cfg := testConfig(url).Webhook
+	cfg.SenderID = "Insider"
+	cfg.APIKey = "key"
+	cfg.APISecret = secret
+
+	client, err := NewVonageClient(&cfg)
+	require.NoError(t, err)
+	return client
+}
+
+func TestVonageClient_SendMessage(t *testing.T) {
+	server := newFakeVonage(t, nil)
+	defer server.Close()
+
+	resp, err := newTestVonageClient(t, server.URL, "secret").
+		SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "vonage-1", resp.MessageID)
+}
+
+func TestVonageClient_ErrorClassification(t *testing.T) {
+	server := newFakeVonage(t, map[string]string{
+		"905551111111": "1", // throttled
+		"905552222222": "5", // internal error
+		"905553333333": "7", // number barred
+		"905554444444": "6", // invalid message
+	})
+	defer server.Close()
+
+	client := newTestVonageClient(t, server.URL, "secret")
+	tests := []struct {
+		to        string
+		permanent bool
+	}{
+		{"+905551111111", false},
+		{"+905552222222", false},
+		{"+905553333333", true},
+		{"+905554444444", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.to, func(t *testing.T) {
+			_, err := client.SendMessage(context.Background(), &message.WebhookRequest{To: tt.to, Content: "hi"})
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, message.IsPermanentFailure(err))
+		})
+	}
+
+	_, err := newTestVonageClient(t, server.URL, "wrong").
+		SendMessage(context.Background(), &message.WebhookRequest{To: "+905555555555", Content: "hi"})
+	assert.True(t, message.IsPermanentFailure(err))
+}
@@ -1,25 +1,20 @@
 package httpclient
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"insider-case/internal/config"
-	"insider-case/internal/constants"
 	"insider-case/internal/domain/message"
 	"insider-case/internal/pkg/logger"
-	"io"
 	"net/http"
-	"time"
 )
 
 type WebhookClient struct {
-	client        *http.Client
-	webhookURL    string
-	authKey       string // X-Ins-Auth-Key header value
-	retryAttempts int
-	retryDelay    time.Duration
+	transport    *transport
+	webhookURL   string
+	method       string
+	successCodes map[int]bool
+	mapper       *PayloadMapper
 }
 
 func NewWebhookClient(cfg *config.Config) message.WebhookClient {
@@ -27,56 +22,44 @@ func NewWebhookClient(cfg *config.Config) message.WebhookClient {
 		panic("webhook URL cannot be empty")
 	}
 
+	transport, err := newTransport(&cfg.Webhook)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	mapper, err := NewPayloadMapper(&cfg.Webhook)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	method := cfg.Webhook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	successCodes := map[int]bool{}
+	for _, code := range cfg.Webhook.SuccessStatusCodes {
+		successCodes[code] = true
+	}
+	if len(successCodes) == 0 {
+		successCodes[http.StatusAccepted] = true
+	}
+
 	return &WebhookClient{
-		client: &http.Client{
-			Timeout: cfg.Webhook.Timeout,
-		},
-		webhookURL:    cfg.Webhook.URL,
-		authKey:       cfg.Webhook.AuthKey,
-		retryAttempts: cfg.Webhook.MaxRetryAttempts,
-		retryDelay:    cfg.Webhook.RetryDelay,
+		transport:    transport,
+		webhookURL:   cfg.Webhook.URL,
+		method:       method,
+		successCodes: successCodes,
+		mapper:       mapper,
 	}
 }
 
+// SendMessage sends the message, retrying transient failures within the
+// attempt budget carried by ctx (see message.RetryPolicy)
 func (c *WebhookClient) SendMessage(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= c.retryAttempts; attempt++ {
-		if attempt > 0 {
-			logger.Warn("Retrying webhook request",
-				"attempt", attempt,
-				"max_attempts", c.retryAttempts+1,
-				"url", c.webhookURL,
-			)
-
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(c.retryDelay):
-			}
-		}
-
-		resp, err := c.sendRequest(ctx, req)
-		if err == nil {
-			return resp, nil
-		}
-
-		lastErr = err
-
-		if httpErr, ok := err.(*HTTPError); ok {
-			if httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 {
-				logger.Error("Client error, not retrying", "status_code", httpErr.StatusCode, "error", err)
-				return nil, err
-			}
-		}
-	}
-
-	logger.Error("All retry attempts failed",
-		"attempts", c.retryAttempts+1,
-		"url", c.webhookURL,
-		"error", lastErr,
-	)
-	return nil, fmt.Errorf("failed after %d attempts: %w", c.retryAttempts+1, lastErr)
+	return c.transport.retry(ctx, func(ctx context.Context, budget *message.AttemptBudget) (*message.WebhookResponse, error) {
+		return c.sendRequest(ctx, budget, req)
+	})
 }
 
 type HTTPError struct {
@@ -89,63 +72,42 @@ func (e *HTTPError) Error() string {
 }
 
 // sendRequest performs a single HTTP request
-func (c *WebhookClient) sendRequest(ctx context.Context, req *message.WebhookRequest) (*message.WebhookResponse, error) {
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		logger.Error("Failed to marshal webhook request", "error", err)
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.webhookURL, bytes.NewBuffer(jsonData))
+func (c *WebhookClient) sendRequest(ctx context.Context, budget *message.AttemptBudget, req *message.WebhookRequest) (*message.WebhookResponse, error) {
+	payload, err := c.mapper.EncodeRequest(req)
 	if err != nil {
-		logger.Error("Failed to create webhook request", "error", err, "url", c.webhookURL)
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		logger.Error("Failed to encode webhook request", "error", err)
+		return nil, fmt.Errorf("failed to encode request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	if c.authKey != "" {
-		httpReq.Header.Set(constants.HeaderAuthKey, c.authKey)
-	}
-
-	resp, err := c.client.Do(httpReq)
+	resp, err := c.transport.do(ctx, budget, c.method, c.webhookURL, c.mapper.ContentType(), payload)
 	if err != nil {
-		logger.Error("Failed to send webhook request", "error", err, "url", c.webhookURL)
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error("Failed to read webhook response", "error", err, "status_code", resp.StatusCode)
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if err := c.transport.throttled(resp); err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusAccepted {
-		logger.Error("Unexpected webhook status code", "status_code", resp.StatusCode, "body", string(body))
-		return nil, &HTTPError{
-			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("unexpected status code: %d, body: %s", resp.StatusCode, string(body)),
-		}
+	if !c.successCodes[resp.StatusCode] {
+		logger.Error("Unexpected webhook status code", "status_code", resp.StatusCode, "body", string(resp.Body))
+		return nil, classifyStatus(resp.StatusCode, resp.Body)
 	}
 
-	if len(body) == 0 {
+	if len(resp.Body) == 0 {
 		logger.Error("Empty response body from webhook", "url", c.webhookURL)
 		return nil, fmt.Errorf("empty response body from webhook")
 	}
 
-	var webhookResp message.WebhookResponse
-	if err := json.Unmarshal(body, &webhookResp); err != nil {
-		logger.Error("Failed to unmarshal webhook response", "error", err, "body", string(body))
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	webhookResp, err := c.mapper.DecodeResponse(resp.Body)
+	if err != nil {
+		logger.Error("Failed to decode webhook response", "error", err, "body", string(resp.Body))
+		return nil, err
 	}
 
 	if webhookResp.MessageID == "" {
-		logger.Error("Empty messageId in webhook response", "url", c.webhookURL, "body", string(body))
+		logger.Error("Empty messageId in webhook response", "url", c.webhookURL, "body", string(resp.Body))
 		return nil, fmt.Errorf("messageId is required in webhook response but was empty")
 	}
 
-	return &webhookResp, nil
+	return webhookResp, nil
 }
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"insider-case/internal/pkg/signature"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.Init("local")
+}
+
+func testConfig(url string) *config.Config {
+	return &config.Config{
+		Webhook: config.WebhookConfig{
+			URL:             url,
+			AuthKey:         "auth-key",
+			Timeout:         5 * time.Second,
+			RetryDelay:      time.Millisecond,
+			SignatureHeader: constants.HeaderSignature,
+			TimestampHeader: constants.HeaderTimestamp,
+		},
+	}
+}
+
+// budgetContext returns a context allowing attempts provider calls for one message
+func budgetContext(ctx context.Context, attempts int) context.Context {
+	policy := message.NewRetryPolicy(attempts, attempts)
+	return message.WithAttemptBudget(ctx, policy.NewBudget(&message.Message{}, nil))
+}
+
+func TestWebhookClient_SignsRequest(t *testing.T) {
+	verifier := signature.NewVerifier(time.Minute, "previous")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifier.VerifyRequest(r, constants.HeaderSignature, constants.HeaderTimestamp); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"abc"}`))
+	}))
+	defer server.Close()
+
+	cfg := testConfig(server.URL)
+	cfg.Webhook.SigningSecret = "current"
+	cfg.Webhook.SigningSecretPrevious = "previous"
+
+	resp, err := NewWebhookClient(cfg).SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.MessageID)
+}
+
+func TestWebhookClient_NoSignatureWithoutSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(constants.HeaderSignature))
+		assert.Empty(t, r.Header.Get(constants.HeaderTimestamp))
+		assert.Equal(t, "auth-key", r.Header.Get(constants.HeaderAuthKey))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"abc"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewWebhookClient(testConfig(server.URL)).SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+}
+
+func TestWebhookClient_RetriesWithinRunBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var recorded []*message.Attempt
+	policy := message.NewRetryPolicy(10, 3)
+	budget := policy.NewBudget(&message.Message{}, func(ctx context.Context, attempt *message.Attempt) error {
+		recorded = append(recorded, attempt)
+		return nil
+	})
+
+	_, err := NewWebhookClient(testConfig(server.URL)).
+		SendMessage(message.WithAttemptBudget(context.Background(), budget), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.Error(t, err)
+	assert.False(t, message.IsPermanentFailure(err))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	require.Len(t, recorded, 3)
+	assert.Equal(t, http.StatusInternalServerError, recorded[2].StatusCode)
+	assert.Equal(t, 3, recorded[2].Number)
+}
+
+func TestWebhookClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := NewWebhookClient(testConfig(server.URL)).
+		SendMessage(budgetContext(context.Background(), 3), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.Error(t, err)
+	assert.True(t, message.IsPermanentFailure(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestWebhookClient_RecordsProviderResponse(t *testing.T) {
+	body := strings.Repeat("x", maxRecordedBodyBytes+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var recorded []*message.Attempt
+	budget := message.NewRetryPolicy(4, 2).NewBudget(&message.Message{}, func(ctx context.Context, attempt *message.Attempt) error {
+		recorded = append(recorded, attempt)
+		return nil
+	})
+
+	_, err := NewWebhookClient(testConfig(server.URL)).
+		SendMessage(message.WithAttemptBudget(context.Background(), budget), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.Error(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, constants.ProviderGeneric, recorded[0].Provider)
+	assert.Equal(t, http.StatusBadRequest, recorded[0].StatusCode)
+	assert.True(t, strings.HasPrefix(recorded[0].ResponseBody, body[:maxRecordedBodyBytes]))
+	assert.Less(t, len(recorded[0].ResponseBody), len(body))
+}
+
+func TestTruncateBody_KeepsRunesWhole(t *testing.T) {
+	// 3 byte runes, so the limit falls inside one
+	body := []byte(strings.Repeat("€", maxRecordedBodyBytes))
+
+	truncated := truncateBody(body)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.True(t, strings.HasSuffix(truncated, "...(truncated)"))
+	kept := strings.TrimSuffix(truncated, "...(truncated)")
+	assert.Equal(t, maxRecordedBodyBytes/3*3, len(kept))
+	assert.Equal(t, "ok", truncateBody([]byte("ok")))
+}
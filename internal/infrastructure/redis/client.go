@@ -26,6 +26,7 @@ func InitRedis(cfg *config.Config) (*redis.Client, error) {
 
 	if err := client.Ping(ctx).Err(); err != nil {
 		logger.Error("Failed to connect to Redis", "error", err, "addr", addr)
+		_ = client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -0,0 +1,194 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"insider-case/internal/domain/message"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	sentCountKey = "sent:count"
+	// sentPagesKey indexes cached pages so they can be invalidated precisely.
+	// Field is the page key, value "<oldest created_at unix nano>:<full>".
+	sentPagesKey = "sent:pages"
+)
+
+// ListCache implements message.ListCache using Redis
+type ListCache struct {
+	client   *redis.Client
+	pageTTL  time.Duration
+	countTTL time.Duration
+}
+
+// NewListCache creates a new ListCache. Pages expire after pageTTL, the count
+// after countTTL.
+func NewListCache(client *redis.Client, pageTTL, countTTL time.Duration) message.ListCache {
+	return &ListCache{
+		client:   client,
+		pageTTL:  pageTTL,
+		countTTL: countTTL,
+	}
+}
+
+// cachedPage is the stored form of message.CachedPage
+type cachedPage struct {
+	Messages []*message.Message `json:"messages"`
+	CachedAt int64              `json:"cached_at"`
+}
+
+// cachedCount is the stored form of message.CachedCount
+type cachedCount struct {
+	Total    int64 `json:"total"`
+	CachedAt int64 `json:"cached_at"`
+}
+
+func sentPageKey(limit, offset int) string {
+	return fmt.Sprintf("sent:page:%d:%d", limit, offset)
+}
+
+// GetSentPage retrieves a cached page, nil when it is not cached
+func (c *ListCache) GetSentPage(ctx context.Context, limit, offset int) (*message.CachedPage, error) {
+	var data cachedPage
+	found, err := c.get(ctx, sentPageKey(limit, offset), &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &message.CachedPage{
+		Messages: data.Messages,
+		CachedAt: time.Unix(0, data.CachedAt),
+	}, nil
+}
+
+// SetSentPage caches a page and records which messages it covers
+func (c *ListCache) SetSentPage(ctx context.Context, limit, offset int, messages []*message.Message) error {
+	key := sentPageKey(limit, offset)
+
+	jsonData, err := json.Marshal(&cachedPage{
+		Messages: messages,
+		CachedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+
+	// Pages are ordered newest first, so the last message is the oldest
+	var oldest int64
+	if len(messages) > 0 {
+		oldest = messages[len(messages)-1].CreatedAt.UnixNano()
+	}
+	full := len(messages) == limit
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, jsonData, c.pageTTL)
+		pipe.HSet(ctx, sentPagesKey, key, fmt.Sprintf("%d:%t", oldest, full))
+		pipe.Expire(ctx, sentPagesKey, c.pageTTL)
+		return nil
+	})
+	return err
+}
+
+// GetSentCount retrieves the cached count, nil when it is not cached
+func (c *ListCache) GetSentCount(ctx context.Context) (*message.CachedCount, error) {
+	var data cachedCount
+	found, err := c.get(ctx, sentCountKey, &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &message.CachedCount{
+		Total:    data.Total,
+		CachedAt: time.Unix(0, data.CachedAt),
+	}, nil
+}
+
+// SetSentCount caches the number of sent messages
+func (c *ListCache) SetSentCount(ctx context.Context, total int64) error {
+	jsonData, err := json.Marshal(&cachedCount{
+		Total:    total,
+		CachedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+
+	return c.client.Set(ctx, sentCountKey, jsonData, c.countTTL).Err()
+}
+
+// InvalidateSent drops the count and every page the message lands on or
+// shifts. Only full pages holding nothing older than the message stay valid,
+// since the message is inserted after them in created_at DESC order.
+func (c *ListCache) InvalidateSent(ctx context.Context, createdAt time.Time) error {
+	pages, err := c.client.HGetAll(ctx, sentPagesKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read cached pages: %w", err)
+	}
+
+	keys := []string{sentCountKey}
+	var fields []string
+	for key, value := range pages {
+		if !pageUnaffected(value, createdAt) {
+			keys = append(keys, key)
+			fields = append(fields, key)
+		}
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		if len(fields) > 0 {
+			pipe.HDel(ctx, sentPagesKey, fields...)
+		}
+		return nil
+	})
+	return err
+}
+
+// Flush drops every cached page and count
+func (c *ListCache) Flush(ctx context.Context) error {
+	pages, err := c.client.HKeys(ctx, sentPagesKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read cached pages: %w", err)
+	}
+
+	keys := append(pages, sentCountKey, sentPagesKey)
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *ListCache) get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	val, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get from cache: %w", err)
+	}
+
+	if err := json.Unmarshal(val, dest); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cache data: %w", err)
+	}
+	return true, nil
+}
+
+// pageUnaffected reports whether a page indexed as value stays valid once a
+// message created at createdAt is sent. Unparsable entries are invalidated.
+func pageUnaffected(value string, createdAt time.Time) bool {
+	oldestStr, fullStr, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	oldest, err := strconv.ParseInt(oldestStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	full, err := strconv.ParseBool(fullStr)
+	if err != nil {
+		return false
+	}
+	return full && oldest > createdAt.UnixNano()
+}
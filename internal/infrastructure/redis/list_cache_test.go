@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"insider-case/internal/domain/message"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentPage builds a page of sent messages, newest first
+func sentPage(base time.Time, ids ...uint) []*message.Message {
+	messages := make([]*message.Message, 0, len(ids))
+	for i, id := range ids {
+		messages = append(messages, &message.Message{
+			ID:        id,
+			Status:    message.MessageStatusSent,
+			CreatedAt: base.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	return messages
+}
+
+func TestListCache_PageAndCountRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	listCache := NewListCache(client, time.Minute, time.Minute)
+
+	page, err := listCache.GetSentPage(ctx, 2, 0)
+	require.NoError(t, err)
+	assert.Nil(t, page)
+
+	require.NoError(t, listCache.SetSentPage(ctx, 2, 0, sentPage(time.Now(), 2, 1)))
+	require.NoError(t, listCache.SetSentCount(ctx, 2))
+
+	page, err = listCache.GetSentPage(ctx, 2, 0)
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Len(t, page.Messages, 2)
+	assert.Equal(t, uint(2), page.Messages[0].ID)
+	assert.False(t, page.CachedAt.IsZero())
+
+	count, err := listCache.GetSentCount(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, count)
+	assert.Equal(t, int64(2), count.Total)
+}
+
+func TestListCache_InvalidatesOnlyAffectedPages(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	listCache := NewListCache(client, time.Minute, time.Minute)
+
+	now := time.Now()
+	require.NoError(t, listCache.SetSentPage(ctx, 2, 0, sentPage(now, 6, 5)))                     // now, now-1m
+	require.NoError(t, listCache.SetSentPage(ctx, 2, 2, sentPage(now.Add(-2*time.Minute), 4, 3))) // now-2m, now-3m
+	require.NoError(t, listCache.SetSentPage(ctx, 2, 4, sentPage(now.Add(-4*time.Minute), 2)))    // short last page
+	require.NoError(t, listCache.SetSentCount(ctx, 5))
+
+	// Sorts between the second and the third message
+	require.NoError(t, listCache.InvalidateSent(ctx, now.Add(-90*time.Second)))
+
+	first, err := listCache.GetSentPage(ctx, 2, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, first, "page holding only newer messages stays cached")
+
+	second, err := listCache.GetSentPage(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Nil(t, second)
+
+	last, err := listCache.GetSentPage(ctx, 2, 4)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	count, err := listCache.GetSentCount(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, count)
+}
+
+func TestListCache_InvalidatesShortPages(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	listCache := NewListCache(client, time.Minute, time.Minute)
+
+	now := time.Now()
+	require.NoError(t, listCache.SetSentPage(ctx, 10, 0, sentPage(now, 2, 1)))
+
+	// Older than everything on the page, but the page has room for it
+	require.NoError(t, listCache.InvalidateSent(ctx, now.Add(-time.Hour)))
+
+	page, err := listCache.GetSentPage(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Nil(t, page)
+}
+
+func TestListCache_Flush(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	listCache := NewListCache(client, time.Minute, time.Minute)
+
+	require.NoError(t, listCache.SetSentPage(ctx, 2, 0, sentPage(time.Now(), 2, 1)))
+	require.NoError(t, listCache.SetSentCount(ctx, 2))
+
+	require.NoError(t, listCache.Flush(ctx))
+
+	assert.Empty(t, server.Keys())
+}
@@ -13,28 +13,37 @@ import (
 
 // CacheRepository implements message.CacheRepository using Redis
 type CacheRepository struct {
-	client *redis.Client
-	ttl    time.Duration
+	client      *redis.Client
+	ttl         time.Duration
+	inFlightTTL time.Duration
 }
 
-// NewCacheRepository creates a new CacheRepository
-func NewCacheRepository(client *redis.Client, ttl time.Duration) message.CacheRepository {
+// NewCacheRepository creates a new CacheRepository. Delivery markers that are
+// still in flight expire after inFlightTTL, sent markers after ttl.
+func NewCacheRepository(client *redis.Client, ttl, inFlightTTL time.Duration) message.CacheRepository {
 	return &CacheRepository{
-		client: client,
-		ttl:    ttl,
+		client:      client,
+		ttl:         ttl,
+		inFlightTTL: inFlightTTL,
 	}
 }
 
-// SetMessageID caches the messageId with sending time
-func (r *CacheRepository) SetMessageID(ctx context.Context, messageID string, sentAt time.Time) error {
-	key := fmt.Sprintf("message:%s", messageID)
+// cachedMessageID is the stored form of message.CachedMessageID
+type cachedMessageID struct {
+	ID        uint   `json:"id,omitempty"`
+	MessageID string `json:"message_id"`
+	SentAt    int64  `json:"sent_at"`
+}
 
-	data := map[string]interface{}{
-		"message_id": messageID,
-		"sent_at":    sentAt.Unix(),
-	}
+// SetMessageID caches the messageId with our message ID and sending time
+func (r *CacheRepository) SetMessageID(ctx context.Context, id uint, messageID string, sentAt time.Time) error {
+	key := fmt.Sprintf("message:%s", messageID)
 
-	jsonData, err := json.Marshal(data)
+	jsonData, err := json.Marshal(&cachedMessageID{
+		ID:        id,
+		MessageID: messageID,
+		SentAt:    sentAt.Unix(),
+	})
 	if err != nil {
 		logger.Error("Failed to marshal cache data", "error", err, "message_id", messageID)
 		return fmt.Errorf("failed to marshal cache data: %w", err)
@@ -43,8 +52,8 @@ func (r *CacheRepository) SetMessageID(ctx context.Context, messageID string, se
 	return r.client.Set(ctx, key, jsonData, r.ttl).Err()
 }
 
-// GetMessageID retrieves cached messageId information
-func (r *CacheRepository) GetMessageID(ctx context.Context, messageID string) (*time.Time, error) {
+// GetMessageID retrieves cached messageId information, nil when it is not cached
+func (r *CacheRepository) GetMessageID(ctx context.Context, messageID string) (*message.CachedMessageID, error) {
 	key := fmt.Sprintf("message:%s", messageID)
 
 	val, err := r.client.Get(ctx, key).Result()
@@ -56,18 +65,66 @@ func (r *CacheRepository) GetMessageID(ctx context.Context, messageID string) (*
 		return nil, fmt.Errorf("failed to get from cache: %w", err)
 	}
 
-	var data map[string]interface{}
+	var data cachedMessageID
 	if err := json.Unmarshal([]byte(val), &data); err != nil {
 		logger.Error("Failed to unmarshal cache data", "error", err, "message_id", messageID)
 		return nil, fmt.Errorf("failed to unmarshal cache data: %w", err)
 	}
 
-	sentAtUnix, ok := data["sent_at"].(float64)
-	if !ok {
-		logger.Error("Invalid sent_at format in cache", "message_id", messageID, "data", data)
-		return nil, fmt.Errorf("invalid sent_at format")
+	return &message.CachedMessageID{
+		ID:        data.ID,
+		MessageID: data.MessageID,
+		SentAt:    time.Unix(data.SentAt, 0),
+	}, nil
+}
+
+// BeginDelivery sets an in-flight marker unless one exists, in which case the existing marker is returned
+func (r *CacheRepository) BeginDelivery(ctx context.Context, id uint, part int) (*message.DeliveryMarker, error) {
+	key := deliveryKey(id, part)
+
+	data, err := json.Marshal(&message.DeliveryMarker{State: message.DeliveryInFlight, UpdatedAt: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delivery marker: %w", err)
+	}
+
+	acquired, err := r.client.SetNX(ctx, key, data, r.inFlightTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to set delivery marker: %w", err)
 	}
+	if acquired {
+		return nil, nil
+	}
+
+	val, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		// Expired in between, the next run acquires it
+		return &message.DeliveryMarker{State: message.DeliveryInFlight, UpdatedAt: time.Now()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery marker: %w", err)
+	}
+
+	var marker message.DeliveryMarker
+	if err := json.Unmarshal([]byte(val), &marker); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal delivery marker: %w", err)
+	}
+	return &marker, nil
+}
+
+// CompleteDelivery replaces the in-flight marker with a sent marker holding the provider message ID
+func (r *CacheRepository) CompleteDelivery(ctx context.Context, id uint, part int, providerID string) error {
+	data, err := json.Marshal(&message.DeliveryMarker{State: message.DeliverySent, ProviderID: providerID, UpdatedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery marker: %w", err)
+	}
+	return r.client.Set(ctx, deliveryKey(id, part), data, r.ttl).Err()
+}
+
+// ClearDelivery removes the delivery marker
+func (r *CacheRepository) ClearDelivery(ctx context.Context, id uint, part int) error {
+	return r.client.Del(ctx, deliveryKey(id, part)).Err()
+}
 
-	sentAt := time.Unix(int64(sentAtUnix), 0)
-	return &sentAt, nil
+func deliveryKey(id uint, part int) string {
+	return fmt.Sprintf("delivery:%d:%d", id, part)
 }
@@ -0,0 +1,282 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// streamField holds our message ID in a stream entry
+const streamField = "id"
+
+// ErrStreamUnavailable is returned while the queue has no Redis client
+var ErrStreamUnavailable = errors.New("redis stream queue is unavailable")
+
+// StreamQueue implements message.Queue on a Redis Stream with a consumer group.
+// Queued rows are claimed from the database and published by Feed; senders
+// read entries with XREADGROUP and take over entries left pending by a
+// crashed consumer with XAUTOCLAIM. The client is set by the Redis
+// supervisor, so the queue follows reconnects.
+type StreamQueue struct {
+	repo message.Repository
+	cfg  *config.QueueConfig
+
+	clientMu sync.Mutex
+	client   *redis.Client
+	grouped  bool // Whether the consumer group was created with client
+
+	mu      sync.Mutex
+	entries map[uint][]string // Stream entry IDs of claimed messages, acknowledged by Ack
+}
+
+// NewStreamQueue creates a StreamQueue, unavailable until SetClient is called
+func NewStreamQueue(repo message.Repository, cfg *config.QueueConfig) *StreamQueue {
+	return &StreamQueue{
+		repo:    repo,
+		cfg:     cfg,
+		entries: make(map[uint][]string),
+	}
+}
+
+// SetClient replaces the Redis client, nil while Redis is unreachable. The
+// stream and consumer group are created on the next use of a new client.
+func (q *StreamQueue) SetClient(client *redis.Client) {
+	q.clientMu.Lock()
+	defer q.clientMu.Unlock()
+	q.client = client
+	q.grouped = false
+}
+
+// Ready reports whether the queue has a Redis client
+func (q *StreamQueue) Ready() bool {
+	q.clientMu.Lock()
+	defer q.clientMu.Unlock()
+	return q.client != nil
+}
+
+// conn returns the current client, creating the stream and consumer group if needed
+func (q *StreamQueue) conn(ctx context.Context) (*redis.Client, error) {
+	q.clientMu.Lock()
+	defer q.clientMu.Unlock()
+	if q.client == nil {
+		return nil, ErrStreamUnavailable
+	}
+	if !q.grouped {
+		err := q.client.XGroupCreateMkStream(ctx, q.cfg.Stream, q.cfg.Group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return nil, fmt.Errorf("failed to create consumer group: %w", err)
+		}
+		q.grouped = true
+	}
+	return q.client, nil
+}
+
+// Publish adds messages to the stream. The messages must already be in processing status.
+func (q *StreamQueue) Publish(ctx context.Context, ids ...uint) error {
+	client, err := q.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	pipe := client.Pipeline()
+	for _, id := range ids {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.cfg.Stream,
+			MaxLen: q.cfg.MaxLen,
+			Approx: true,
+			Values: map[string]interface{}{streamField: id},
+		})
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Feed moves a batch of queued rows into the stream. Rows stay queued while
+// Redis is unavailable. If publishing fails the rows are returned to the
+// queue; entries that were added anyway are skipped by Claim once the row is
+// no longer in processing status.
+func (q *StreamQueue) Feed(ctx context.Context) error {
+	if _, err := q.conn(ctx); err != nil {
+		return err
+	}
+
+	messages, err := q.repo.GetUnsentMessages(ctx, q.cfg.FeedBatch)
+	if err != nil {
+		return fmt.Errorf("failed to claim queued messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+
+	if err := q.Publish(ctx, ids...); err != nil {
+		for _, id := range ids {
+			if revertErr := q.repo.UpdateMessageStatusOnly(ctx, id, message.MessageStatusQueued); revertErr != nil {
+				logger.Warn("Failed to revert message status to queued",
+					"message_id", id,
+					"error", revertErr,
+				)
+			}
+		}
+		return fmt.Errorf("failed to publish messages: %w", err)
+	}
+
+	logger.Info("Published queued messages to stream", "count", len(ids), "stream", q.cfg.Stream)
+	return nil
+}
+
+// Claim takes over entries idle for ClaimIdle, then reads new entries,
+// waiting up to Block for them
+func (q *StreamQueue) Claim(ctx context.Context, limit int) ([]*message.Message, error) {
+	client, err := q.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := q.autoClaim(ctx, client, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idle entries: %w", err)
+	}
+
+	if len(entries) < limit {
+		block := q.cfg.Block
+		if block <= 0 {
+			block = -1 // Do not block
+		}
+		streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.cfg.Group,
+			Consumer: q.cfg.Consumer,
+			Streams:  []string{q.cfg.Stream, ">"},
+			Count:    int64(limit - len(entries)),
+			Block:    block,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("failed to read stream: %w", err)
+		}
+		for _, stream := range streams {
+			entries = append(entries, stream.Messages...)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return q.load(ctx, client, entries)
+}
+
+// autoClaim runs XAUTOCLAIM. The reply is parsed here because the client only
+// understands the two element reply of Redis 6.2, Redis 7 adds deleted IDs.
+func (q *StreamQueue) autoClaim(ctx context.Context, client *redis.Client, limit int) ([]redis.XMessage, error) {
+	reply, err := client.Do(ctx, "XAUTOCLAIM", q.cfg.Stream, q.cfg.Group, q.cfg.Consumer,
+		q.cfg.ClaimIdle.Milliseconds(), "0-0", "COUNT", limit).Slice()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 {
+		return nil, fmt.Errorf("unexpected XAUTOCLAIM reply: %v", reply)
+	}
+
+	raw, _ := reply[1].([]interface{})
+	entries := make([]redis.XMessage, 0, len(raw))
+	for _, item := range raw {
+		entry, ok := item.([]interface{})
+		if !ok || len(entry) != 2 {
+			continue // Deleted entry
+		}
+		id, _ := entry[0].(string)
+		fields, _ := entry[1].([]interface{})
+		values := make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if key, ok := fields[i].(string); ok {
+				values[key] = fields[i+1]
+			}
+		}
+		entries = append(entries, redis.XMessage{ID: id, Values: values})
+	}
+	return entries, nil
+}
+
+// Ack acknowledges the stream entries of a claimed message
+func (q *StreamQueue) Ack(ctx context.Context, msg *message.Message) error {
+	q.mu.Lock()
+	entryIDs := q.entries[msg.ID]
+	delete(q.entries, msg.ID)
+	q.mu.Unlock()
+
+	if len(entryIDs) == 0 {
+		return nil
+	}
+	client, err := q.conn(ctx)
+	if err != nil {
+		// The entries stay pending and are claimed again after ClaimIdle
+		return err
+	}
+	return client.XAck(ctx, q.cfg.Stream, q.cfg.Group, entryIDs...).Err()
+}
+
+// load resolves entries to messages in processing status. Entries of messages
+// that were already handled, or no longer exist, are acknowledged right away.
+func (q *StreamQueue) load(ctx context.Context, client *redis.Client, entries []redis.XMessage) ([]*message.Message, error) {
+	entryIDs := make(map[uint][]string)
+	var ids []uint
+	var stale []string
+	for _, entry := range entries {
+		id, err := strconv.ParseUint(fmt.Sprint(entry.Values[streamField]), 10, 64)
+		if err != nil {
+			logger.Warn("Dropping malformed stream entry", "entry_id", entry.ID, "values", entry.Values)
+			stale = append(stale, entry.ID)
+			continue
+		}
+		if _, ok := entryIDs[uint(id)]; !ok {
+			ids = append(ids, uint(id))
+		}
+		entryIDs[uint(id)] = append(entryIDs[uint(id)], entry.ID)
+	}
+
+	var messages []*message.Message
+	if len(ids) > 0 {
+		var err error
+		if messages, err = q.repo.GetMessagesByIDs(ctx, ids); err != nil {
+			// Entries stay pending and are claimed again after ClaimIdle
+			return nil, err
+		}
+	}
+
+	claimed := make([]*message.Message, 0, len(messages))
+	q.mu.Lock()
+	for _, msg := range messages {
+		if msg.Status == message.MessageStatusProcessing {
+			claimed = append(claimed, msg)
+			q.entries[msg.ID] = append(q.entries[msg.ID], entryIDs[msg.ID]...)
+		} else {
+			stale = append(stale, entryIDs[msg.ID]...)
+		}
+		delete(entryIDs, msg.ID)
+	}
+	q.mu.Unlock()
+	for _, missing := range entryIDs {
+		stale = append(stale, missing...)
+	}
+
+	if len(stale) > 0 {
+		if err := client.XAck(ctx, q.cfg.Stream, q.cfg.Group, stale...).Err(); err != nil {
+			logger.Warn("Failed to acknowledge stale stream entries", "count", len(stale), "error", err)
+		}
+	}
+	return claimed, nil
+}
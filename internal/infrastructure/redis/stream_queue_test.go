@@ -0,0 +1,196 @@
+package redis
+
+import (
+	"context"
+	"insider-case/internal/config"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.Init("test")
+}
+
+// queueRepo is an in-memory message table
+type queueRepo struct {
+	message.Repository
+	mu       sync.Mutex
+	messages map[uint]*message.Message
+}
+
+func newQueueRepo(ids ...uint) *queueRepo {
+	repo := &queueRepo{messages: make(map[uint]*message.Message)}
+	for _, id := range ids {
+		repo.messages[id] = &message.Message{ID: id, Status: message.MessageStatusQueued}
+	}
+	return repo
+}
+
+func (r *queueRepo) GetUnsentMessages(ctx context.Context, limit int) ([]*message.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*message.Message
+	for id := uint(1); id <= uint(len(r.messages)) && len(claimed) < limit; id++ {
+		if msg := r.messages[id]; msg != nil && msg.Status == message.MessageStatusQueued {
+			msg.Status = message.MessageStatusProcessing
+			claimed = append(claimed, msg)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *queueRepo) GetMessagesByIDs(ctx context.Context, ids []uint) ([]*message.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*message.Message
+	for _, id := range ids {
+		if msg, ok := r.messages[id]; ok {
+			copied := *msg
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (r *queueRepo) UpdateMessageStatusOnly(ctx context.Context, id uint, status message.MessageStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[id].Status = status
+	return nil
+}
+
+func newTestStreamQueue(t *testing.T, client *redis.Client, repo message.Repository, consumer string) *StreamQueue {
+	queue := NewStreamQueue(repo, &config.QueueConfig{
+		Stream:       "messages:queue",
+		Group:        "senders",
+		Consumer:     consumer,
+		Block:        100 * time.Millisecond,
+		ClaimIdle:    time.Minute,
+		MaxLen:       1000,
+		FeedInterval: 10 * time.Millisecond,
+		FeedBatch:    10,
+	})
+	queue.SetClient(client)
+	return queue
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return server, client
+}
+
+func pendingCount(t *testing.T, client *redis.Client) int64 {
+	pending, err := client.XPending(context.Background(), "messages:queue", "senders").Result()
+	require.NoError(t, err)
+	return pending.Count
+}
+
+func TestStreamQueue_FeedClaimAck(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	repo := newQueueRepo(1, 2, 3)
+	queue := newTestStreamQueue(t, client, repo, "a")
+
+	require.NoError(t, queue.Feed(ctx))
+	assert.Equal(t, message.MessageStatusProcessing, repo.messages[3].Status)
+
+	claimed, err := queue.Claim(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, uint(1), claimed[0].ID)
+	assert.Equal(t, int64(2), pendingCount(t, client))
+
+	for _, msg := range claimed {
+		require.NoError(t, queue.Ack(ctx, msg))
+	}
+	assert.Equal(t, int64(0), pendingCount(t, client))
+
+	claimed, err = queue.Claim(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, uint(3), claimed[0].ID)
+}
+
+func TestStreamQueue_SkipsEntriesOfHandledMessages(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	repo := newQueueRepo(1, 2)
+	repo.messages[2].Status = message.MessageStatusSent
+	queue := newTestStreamQueue(t, client, repo, "a")
+
+	require.NoError(t, queue.Publish(ctx, 1, 2, 99))
+	repo.messages[1].Status = message.MessageStatusProcessing
+
+	claimed, err := queue.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, uint(1), claimed[0].ID)
+	assert.Equal(t, int64(1), pendingCount(t, client))
+}
+
+func TestStreamQueue_TakesOverIdleEntries(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	repo := newQueueRepo(1)
+	crashed := newTestStreamQueue(t, client, repo, "a")
+	survivor := newTestStreamQueue(t, client, repo, "b")
+
+	now := time.Now()
+	server.SetTime(now)
+	require.NoError(t, crashed.Feed(ctx))
+	claimed, err := crashed.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	claimed, err = survivor.Claim(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	server.SetTime(now.Add(2 * time.Minute))
+	claimed, err = survivor.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, uint(1), claimed[0].ID)
+
+	require.NoError(t, survivor.Ack(ctx, claimed[0]))
+	assert.Equal(t, int64(0), pendingCount(t, client))
+}
+
+func TestStreamQueue_FollowsClientChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := newQueueRepo(1, 2)
+	queue := newTestStreamQueue(t, nil, repo, "a")
+
+	assert.False(t, queue.Ready())
+	assert.ErrorIs(t, queue.Feed(ctx), ErrStreamUnavailable)
+	_, err := queue.Claim(ctx, 10)
+	assert.ErrorIs(t, err, ErrStreamUnavailable)
+	assert.Equal(t, message.MessageStatusQueued, repo.messages[1].Status, "rows stay queued without Redis")
+
+	_, first := newTestRedis(t)
+	queue.SetClient(first)
+	require.NoError(t, queue.Feed(ctx))
+	claimed, err := queue.Claim(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	// A new server without the stream, as after a restart without persistence
+	_, second := newTestRedis(t)
+	queue.SetClient(second)
+	repo.messages[2].Status = message.MessageStatusQueued
+	require.NoError(t, queue.Feed(ctx))
+	claimed, err = queue.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, uint(2), claimed[0].ID)
+}
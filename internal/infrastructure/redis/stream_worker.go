@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/pkg/logger"
+	"sync"
+	"time"
+)
+
+// defaultFeedInterval is used when QUEUE_FEED_INTERVAL is not positive
+const defaultFeedInterval = time.Second
+
+// StreamWorker drives a StreamQueue. Its feed loop moves queued rows into the
+// stream every FeedInterval, and its consume loop waits on XREADGROUP and
+// hands entries to the processor as soon as they arrive, instead of on the
+// scheduler interval. It is the only loop reading the stream.
+type StreamWorker struct {
+	queue     *StreamQueue
+	processor message.MessageProcessor
+	active    func() bool // Consuming pauses while this returns false
+	timeout   time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewStreamWorker creates a StreamWorker that consumes until it is gated by
+// Scheduler; timeout bounds each feed and processing run.
+func NewStreamWorker(queue *StreamQueue, processor message.MessageProcessor, timeout time.Duration) *StreamWorker {
+	return &StreamWorker{
+		queue:     queue,
+		processor: processor,
+		active:    func() bool { return true },
+		timeout:   timeout,
+	}
+}
+
+// Scheduler returns the scheduler that switches consuming on and off. Its
+// ticks do nothing, so the stream is not also read on the scheduler
+// interval. Call it before Start.
+func (w *StreamWorker) Scheduler(interval, processingTimeout time.Duration) *message.Scheduler {
+	scheduler := message.NewScheduler(gateProcessor{}, interval, processingTimeout)
+	w.active = scheduler.IsRunning
+	return scheduler
+}
+
+// gateProcessor is the processor of a scheduler that only gates the worker
+type gateProcessor struct{}
+
+func (gateProcessor) SendPendingMessages(ctx context.Context) error {
+	return nil
+}
+
+// Start runs the feed and consume loops until Stop is called
+func (w *StreamWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+		w.feed(ctx)
+	}()
+	go func() {
+		defer w.wg.Done()
+		w.consume(ctx)
+	}()
+}
+
+// Stop stops both loops and waits for them to finish
+func (w *StreamWorker) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+		w.wg.Wait()
+	}
+}
+
+func (w *StreamWorker) feed(ctx context.Context) {
+	ticker := time.NewTicker(w.idle())
+	defer ticker.Stop()
+
+	for {
+		if w.queue.Ready() {
+			runCtx, cancel := context.WithTimeout(ctx, w.timeout)
+			if err := w.queue.Feed(runCtx); err != nil && !errors.Is(err, ErrStreamUnavailable) && ctx.Err() == nil {
+				logger.Warn("Failed to feed stream", "stream", w.queue.cfg.Stream, "error", err)
+			}
+			cancel()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// consume processes entries back to back. Each run blocks on XREADGROUP for up
+// to Block, so an idle queue does not spin.
+func (w *StreamWorker) consume(ctx context.Context) {
+	for ctx.Err() == nil {
+		if !w.active() || !w.queue.Ready() {
+			w.wait(ctx)
+			continue
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, w.timeout)
+		err := w.processor.SendPendingMessages(runCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to send queued messages", "error", err)
+		}
+		if err != nil || w.queue.cfg.Block <= 0 {
+			w.wait(ctx)
+		}
+	}
+}
+
+// idle is how long the loops wait when there is nothing to do
+func (w *StreamWorker) idle() time.Duration {
+	if w.queue.cfg.FeedInterval <= 0 {
+		return defaultFeedInterval
+	}
+	return w.queue.cfg.FeedInterval
+}
+
+func (w *StreamWorker) wait(ctx context.Context) {
+	timer := time.NewTimer(w.idle())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
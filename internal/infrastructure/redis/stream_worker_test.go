@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueProcessor claims from the queue and acknowledges each message, as the
+// service does after storing the outcome. It counts reads per message and
+// overlapping calls, which would mean a second loop reads the stream.
+type queueProcessor struct {
+	queue *StreamQueue
+
+	mu          sync.Mutex
+	reads       map[uint]int
+	running     int
+	overlapping bool
+}
+
+func (p *queueProcessor) SendPendingMessages(ctx context.Context) error {
+	p.mu.Lock()
+	p.running++
+	p.overlapping = p.overlapping || p.running > 1
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+
+	messages, err := p.queue.Claim(ctx, 10)
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		p.mu.Lock()
+		p.reads[msg.ID]++
+		p.mu.Unlock()
+		if err := p.queue.Ack(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *queueProcessor) delivered() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := 0
+	for _, reads := range p.reads {
+		count += reads
+	}
+	return count
+}
+
+func TestStreamWorker_DeliversWhileSchedulerRuns(t *testing.T) {
+	_, client := newTestRedis(t)
+	repo := newQueueRepo(1, 2, 3)
+	queue := newTestStreamQueue(t, client, repo, "a")
+	processor := &queueProcessor{queue: queue, reads: make(map[uint]int)}
+
+	worker := NewStreamWorker(queue, processor, time.Second)
+	scheduler := worker.Scheduler(10*time.Millisecond, time.Second)
+	worker.Start()
+	defer worker.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Zero(t, processor.delivered(), "nothing is delivered while the scheduler is stopped")
+
+	require.NoError(t, scheduler.Start())
+	defer func() { _ = scheduler.Stop() }()
+	assert.Eventually(t, func() bool { return processor.delivered() == 3 }, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return pendingCount(t, client) == 0 }, time.Second, 10*time.Millisecond)
+
+	// Let the scheduler tick a few more times
+	time.Sleep(50 * time.Millisecond)
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	for id, reads := range processor.reads {
+		assert.Equal(t, 1, reads, "message %d is read by one loop only", id)
+	}
+	assert.False(t, processor.overlapping, "only the worker reads the stream")
+}
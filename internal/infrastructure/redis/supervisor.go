@@ -0,0 +1,165 @@
+package redis
+
+import (
+	"context"
+	"insider-case/internal/config"
+	"insider-case/internal/pkg/logger"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Connection states reported by Supervisor.Status
+const (
+	StateOK            = "ok"
+	StateReconnecting  = "reconnecting"   // Never connected, retrying in the background
+	StateDegraded      = "degraded"       // Connected before, pings are failing
+	StateNotConfigured = "not_configured" // No supervisor
+)
+
+// Supervisor keeps the Redis connection alive. It retries the first
+// connection with exponential backoff, pings the server afterwards and calls
+// onChange with the client when Redis becomes usable and with nil when it is lost.
+type Supervisor struct {
+	cfg      *config.Config
+	onChange func(client *redis.Client)
+
+	mu     sync.RWMutex
+	client *redis.Client
+	state  string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSupervisor creates a new Supervisor
+func NewSupervisor(cfg *config.Config, onChange func(client *redis.Client)) *Supervisor {
+	return &Supervisor{
+		cfg:      cfg,
+		onChange: onChange,
+		state:    StateReconnecting,
+	}
+}
+
+// Connect makes the first connection attempt synchronously
+func (s *Supervisor) Connect() error {
+	client, err := InitRedis(s.cfg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.client = client
+	s.state = StateOK
+	s.mu.Unlock()
+
+	s.onChange(client)
+	return nil
+}
+
+// Start supervises the connection in the background until Stop is called
+func (s *Supervisor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Stop stops supervising and closes the client
+func (s *Supervisor) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.wg.Wait()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		_ = s.client.Close()
+	}
+}
+
+// Client returns the client, nil until the first connection succeeds
+func (s *Supervisor) Client() *redis.Client {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// Status returns the connection state for health reporting
+func (s *Supervisor) Status() string {
+	if s == nil {
+		return StateNotConfigured
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+func (s *Supervisor) run(ctx context.Context) {
+	minBackoff, maxBackoff := s.cfg.Redis.ReconnectMinBackoff, s.cfg.Redis.ReconnectMaxBackoff
+	backoff := minBackoff
+
+	for {
+		var wait time.Duration
+		if s.check(ctx) {
+			backoff = minBackoff
+			wait = s.cfg.Redis.HealthInterval
+		} else {
+			wait = backoff
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// check connects or pings once and reports whether Redis is usable
+func (s *Supervisor) check(ctx context.Context) bool {
+	client := s.Client()
+	if client == nil {
+		if err := s.Connect(); err != nil {
+			return false
+		}
+		logger.Info("Redis connection established")
+		return true
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, s.cfg.Redis.ConnectTimeout)
+	err := client.Ping(pingCtx).Err()
+	cancel()
+
+	s.mu.Lock()
+	previous := s.state
+	if err != nil {
+		s.state = StateDegraded
+	} else {
+		s.state = StateOK
+	}
+	s.mu.Unlock()
+
+	switch {
+	case err != nil && previous == StateOK:
+		logger.Error("Redis connection lost, continuing without cache", "error", err)
+		s.onChange(nil)
+	case err == nil && previous != StateOK:
+		logger.Info("Redis connection restored")
+		s.onChange(client)
+	}
+	return err == nil
+}
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"insider-case/internal/config"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clientRecorder records the clients handed to onChange
+type clientRecorder struct {
+	mu      sync.Mutex
+	current *redis.Client
+	calls   int
+}
+
+func (r *clientRecorder) onChange(client *redis.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = client
+	r.calls++
+}
+
+func (r *clientRecorder) connected() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current != nil
+}
+
+func supervisorConfig(t *testing.T, addr string) *config.Config {
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	return &config.Config{Redis: config.RedisConfig{
+		Host:                host,
+		Port:                port,
+		ConnectTimeout:      200 * time.Millisecond,
+		ReconnectMinBackoff: 10 * time.Millisecond,
+		ReconnectMaxBackoff: 40 * time.Millisecond,
+		HealthInterval:      10 * time.Millisecond,
+	}}
+}
+
+func TestSupervisor_ReconnectsAfterStartupFailure(t *testing.T) {
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	recorder := &clientRecorder{}
+	supervisor := NewSupervisor(supervisorConfig(t, addr), recorder.onChange)
+	require.Error(t, supervisor.Connect())
+	assert.Equal(t, StateReconnecting, supervisor.Status())
+	assert.Nil(t, supervisor.Client())
+
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	require.NoError(t, server.Restart())
+	assert.Eventually(t, recorder.connected, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateOK, supervisor.Status())
+	assert.NotNil(t, supervisor.Client())
+}
+
+func TestSupervisor_ReportsDegradedAndRecovers(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	recorder := &clientRecorder{}
+	supervisor := NewSupervisor(supervisorConfig(t, server.Addr()), recorder.onChange)
+	require.NoError(t, supervisor.Connect())
+	assert.True(t, recorder.connected())
+
+	supervisor.Start()
+	defer supervisor.Stop()
+
+	server.Close()
+	assert.Eventually(t, func() bool { return supervisor.Status() == StateDegraded }, 2*time.Second, 10*time.Millisecond)
+	assert.False(t, recorder.connected())
+
+	require.NoError(t, server.Restart())
+	assert.Eventually(t, recorder.connected, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateOK, supervisor.Status())
+}
+
+func TestSupervisor_NilIsNotConfigured(t *testing.T) {
+	var supervisor *Supervisor
+	assert.Equal(t, StateNotConfigured, supervisor.Status())
+	assert.Nil(t, supervisor.Client())
+}
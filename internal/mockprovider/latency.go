@@ -0,0 +1,44 @@
+package mockprovider
+
+import (
+	"fmt"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"math/rand"
+	"time"
+)
+
+// latencyFunc returns the delay applied before answering a request
+type latencyFunc func(rng *rand.Rand) time.Duration
+
+func newLatency(cfg config.LatencyConfig) (latencyFunc, error) {
+	switch cfg.Distribution {
+	case "", constants.LatencyFixed:
+		return func(*rand.Rand) time.Duration {
+			return cfg.Min
+		}, nil
+	case constants.LatencyUniform:
+		if cfg.Max < cfg.Min {
+			return nil, fmt.Errorf("uniform latency requires max >= min")
+		}
+		return func(rng *rand.Rand) time.Duration {
+			if cfg.Max == cfg.Min {
+				return cfg.Min
+			}
+			return cfg.Min + time.Duration(rng.Int63n(int64(cfg.Max-cfg.Min)))
+		}, nil
+	case constants.LatencyNormal:
+		return func(rng *rand.Rand) time.Duration {
+			d := cfg.Mean + time.Duration(rng.NormFloat64()*float64(cfg.StdDev))
+			if d < cfg.Min {
+				d = cfg.Min
+			}
+			if cfg.Max > 0 && d > cfg.Max {
+				d = cfg.Max
+			}
+			return d
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported latency distribution: %s", cfg.Distribution)
+	}
+}
@@ -0,0 +1,82 @@
+package mockprovider
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RecordedRequest is a request received by the mock provider and the answer it got
+type RecordedRequest struct {
+	ReceivedAt time.Time         `json:"received_at"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	StatusCode int               `json:"status_code"`
+	MessageID  string            `json:"message_id,omitempty"`
+	Fault      string            `json:"fault,omitempty"`
+	Latency    time.Duration     `json:"latency"`
+}
+
+// RecordedCallback is a delivery receipt sent by the mock provider
+type RecordedCallback struct {
+	SentAt     time.Time `json:"sent_at"`
+	MessageID  string    `json:"message_id"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Recorder keeps everything the mock provider received and sent
+type Recorder struct {
+	mu        sync.RWMutex
+	requests  []RecordedRequest
+	callbacks []RecordedCallback
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) addRequest(req RecordedRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+}
+
+func (r *Recorder) addCallback(cb RecordedCallback) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callbacks = append(r.callbacks, cb)
+}
+
+// Requests returns a copy of the recorded requests
+func (r *Recorder) Requests() []RecordedRequest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]RecordedRequest(nil), r.requests...)
+}
+
+// Callbacks returns a copy of the recorded delivery receipts
+func (r *Recorder) Callbacks() []RecordedCallback {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]RecordedCallback(nil), r.callbacks...)
+}
+
+// Reset clears everything recorded so far
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = nil
+	r.callbacks = nil
+}
+
+func flattenHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name := range header {
+		headers[name] = header.Get(name)
+	}
+	return headers
+}
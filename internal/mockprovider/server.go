@@ -0,0 +1,291 @@
+package mockprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/pkg/logger"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Injected faults
+const (
+	faultThrottle    = "throttle"
+	faultUnavailable = "unavailable"
+	faultError       = "error"
+	faultMalformed   = "malformed"
+)
+
+// Server is a local SMS provider implementing the contract WebhookClient expects:
+// 202 with {"message","messageId"} for requests carrying the right auth key
+type Server struct {
+	cfg      *config.MockProviderConfig
+	recorder *Recorder
+	latency  latencyFunc
+	client   *http.Client
+
+	mu     sync.Mutex
+	rng    *mathrand.Rand
+	faults config.FaultConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// sendRequest is the body WebhookClient sends by default
+type sendRequest struct {
+	To      string `json:"to"`
+	Content string `json:"content"`
+}
+
+// deliveryReceipt is posted to the callback URL once a message is "delivered"
+type deliveryReceipt struct {
+	MessageID   string    `json:"messageId"`
+	To          string    `json:"to"`
+	Status      string    `json:"status"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+}
+
+// NewServer creates a new Server
+func NewServer(cfg *config.MockProviderConfig) (*Server, error) {
+	latency, err := newLatency(cfg.Latency)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		cfg:      cfg,
+		recorder: NewRecorder(),
+		latency:  latency,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		rng:      mathrand.New(mathrand.NewSource(seed)),
+		faults:   cfg.Faults,
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+// Recorder returns the recorder of received requests and sent callbacks
+func (s *Server) Recorder() *Recorder {
+	return s.recorder
+}
+
+// Faults returns the current fault injection rates
+func (s *Server) Faults() config.FaultConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults
+}
+
+// SetFaults replaces the fault injection rates at runtime
+func (s *Server) SetFaults(faults config.FaultConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+}
+
+// Close cancels pending delivery receipts and waits for in-flight ones
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Handler returns the HTTP handler. Control endpoints live under /_mock,
+// every other request is treated as a send.
+func (s *Server) Handler() http.Handler {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	control := router.Group(constants.MockControlBasePath)
+	{
+		control.GET(constants.MockRequestsPath, s.listRequests)
+		control.DELETE(constants.MockRequestsPath, s.resetRequests)
+		control.GET(constants.MockFaultsPath, s.getFaults)
+		control.PUT(constants.MockFaultsPath, s.putFaults)
+	}
+	router.NoRoute(s.send)
+
+	return router
+}
+
+func (s *Server) send(c *gin.Context) {
+	body, _ := io.ReadAll(c.Request.Body)
+	record := RecordedRequest{
+		ReceivedAt: time.Now(),
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		Headers:    flattenHeaders(c.Request.Header),
+		Body:       string(body),
+	}
+	defer func() {
+		record.StatusCode = c.Writer.Status()
+		s.recorder.addRequest(record)
+	}()
+
+	if s.cfg.AuthKey != "" && c.GetHeader(s.cfg.AuthHeader) != s.cfg.AuthKey {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		return
+	}
+
+	fault, delay := s.roll()
+	record.Latency = delay
+	record.Fault = fault
+	select {
+	case <-c.Request.Context().Done():
+		return
+	case <-time.After(delay):
+	}
+
+	faults := s.Faults()
+	switch fault {
+	case faultThrottle:
+		c.Header("Retry-After", strconv.Itoa(int(faults.RetryAfter.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"message": "Too Many Requests"})
+		return
+	case faultUnavailable:
+		c.Header("Retry-After", strconv.Itoa(int(faults.RetryAfter.Seconds())))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "Service Unavailable"})
+		return
+	case faultError:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Internal Server Error"})
+		return
+	case faultMalformed:
+		c.Data(http.StatusAccepted, "application/json", []byte(`{"message":"Accepted","messageId":`))
+		return
+	}
+
+	var req sendRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.To == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid request body"})
+		return
+	}
+
+	messageID := newMessageID()
+	record.MessageID = messageID
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":   "Accepted",
+		"messageId": messageID,
+	})
+
+	if s.cfg.CallbackURL != "" {
+		s.scheduleReceipt(deliveryReceipt{MessageID: messageID, To: req.To, Status: constants.MessageStatusDelivered})
+	}
+}
+
+// roll picks the fault to inject (empty for none) and the latency to apply
+func (s *Server) roll() (string, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delay := s.latency(s.rng)
+	r := s.rng.Float64()
+	for _, candidate := range []struct {
+		name string
+		rate float64
+	}{
+		{faultThrottle, s.faults.ThrottleRate},
+		{faultUnavailable, s.faults.UnavailableRate},
+		{faultError, s.faults.ErrorRate},
+		{faultMalformed, s.faults.MalformedRate},
+	} {
+		if r < candidate.rate {
+			return candidate.name, delay
+		}
+		r -= candidate.rate
+	}
+	return "", delay
+}
+
+// scheduleReceipt posts a delivery receipt to the callback URL after
+// CallbackDelay, normally the service's receipt endpoint
+func (s *Server) scheduleReceipt(receipt deliveryReceipt) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.cfg.CallbackDelay):
+		}
+
+		receipt.DeliveredAt = time.Now().UTC()
+		callback := RecordedCallback{SentAt: time.Now(), MessageID: receipt.MessageID}
+		defer func() {
+			s.recorder.addCallback(callback)
+		}()
+
+		payload, _ := json.Marshal(receipt)
+		req, err := http.NewRequest(http.MethodPost, s.cfg.CallbackURL, bytes.NewReader(payload))
+		if err != nil {
+			callback.Error = err.Error()
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if s.cfg.CallbackToken != "" {
+			req.Header.Set(constants.HeaderAccessToken, s.cfg.CallbackToken)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			logger.Warn("Failed to send delivery receipt", "message_id", receipt.MessageID, "error", err)
+			callback.Error = err.Error()
+			return
+		}
+		_ = resp.Body.Close()
+		callback.StatusCode = resp.StatusCode
+	}()
+}
+
+func (s *Server) listRequests(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"requests":  s.recorder.Requests(),
+		"callbacks": s.recorder.Callbacks(),
+	})
+}
+
+func (s *Server) resetRequests(c *gin.Context) {
+	s.recorder.Reset()
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) getFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, s.Faults())
+}
+
+func (s *Server) putFaults(c *gin.Context) {
+	faults := s.Faults()
+	if err := c.ShouldBindJSON(&faults); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	s.SetFaults(faults)
+	c.JSON(http.StatusOK, faults)
+}
+
+func newMessageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
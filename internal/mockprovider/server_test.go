@@ -0,0 +1,212 @@
+package mockprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"insider-case/internal/config"
+	"insider-case/internal/constants"
+	"insider-case/internal/domain/message"
+	"insider-case/internal/infrastructure/httpclient"
+	"insider-case/internal/pkg/logger"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	logger.Init("test")
+	gin.SetMode(gin.TestMode)
+}
+
+func testConfig() *config.MockProviderConfig {
+	return &config.MockProviderConfig{
+		AuthHeader:    constants.HeaderAuthKey,
+		AuthKey:       "secret",
+		Latency:       config.LatencyConfig{Distribution: constants.LatencyFixed},
+		Faults:        config.FaultConfig{RetryAfter: 2 * time.Second},
+		CallbackDelay: 10 * time.Millisecond,
+		Seed:          1,
+	}
+}
+
+func startServer(t *testing.T, cfg *config.MockProviderConfig) (*Server, *httptest.Server) {
+	t.Helper()
+	provider, err := NewServer(cfg)
+	require.NoError(t, err)
+	server := httptest.NewServer(provider.Handler())
+	t.Cleanup(func() {
+		server.Close()
+		provider.Close()
+	})
+	return provider, server
+}
+
+func post(t *testing.T, url, authKey string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"to":"+905551111111","content":"hi"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constants.HeaderAuthKey, authKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestServer_SatisfiesWebhookClientContract(t *testing.T) {
+	provider, server := startServer(t, testConfig())
+
+	webhookCfg := &config.Config{Webhook: config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "secret",
+		Timeout:            5 * time.Second,
+		Method:             http.MethodPost,
+		SuccessStatusCodes: []int{http.StatusAccepted},
+		Auth:               config.WebhookAuthConfig{Type: constants.AuthTypeHeader, HeaderName: constants.HeaderAuthKey},
+	}}
+	resp, err := httpclient.NewWebhookClient(webhookCfg).SendMessage(context.Background(), &message.WebhookRequest{To: "+905551111111", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "Accepted", resp.Message)
+	assert.NotEmpty(t, resp.MessageID)
+
+	requests := provider.Recorder().Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.StatusAccepted, requests[0].StatusCode)
+	assert.Equal(t, resp.MessageID, requests[0].MessageID)
+	assert.JSONEq(t, `{"to":"+905551111111","content":"hi"}`, requests[0].Body)
+}
+
+func TestServer_RejectsWrongAuthKey(t *testing.T) {
+	provider, server := startServer(t, testConfig())
+
+	resp := post(t, server.URL, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Len(t, provider.Recorder().Requests(), 1)
+}
+
+func TestServer_InjectsFaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		faults     config.FaultConfig
+		statusCode int
+		retryAfter string
+	}{
+		{"throttle", config.FaultConfig{ThrottleRate: 1, RetryAfter: 2 * time.Second}, http.StatusTooManyRequests, "2"},
+		{"unavailable", config.FaultConfig{UnavailableRate: 1, RetryAfter: 2 * time.Second}, http.StatusServiceUnavailable, "2"},
+		{"error", config.FaultConfig{ErrorRate: 1}, http.StatusInternalServerError, ""},
+		{"malformed", config.FaultConfig{MalformedRate: 1}, http.StatusAccepted, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server := startServer(t, testConfig())
+			provider.SetFaults(tt.faults)
+
+			resp := post(t, server.URL, "secret")
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+			assert.Equal(t, tt.retryAfter, resp.Header.Get("Retry-After"))
+
+			var body map[string]interface{}
+			decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+			if tt.name == "malformed" {
+				assert.Error(t, decodeErr)
+			}
+			assert.Equal(t, tt.name, provider.Recorder().Requests()[0].Fault)
+		})
+	}
+}
+
+func TestServer_UpdatesFaultsAtRuntime(t *testing.T) {
+	provider, server := startServer(t, testConfig())
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+constants.MockControlBasePath+constants.MockFaultsPath, bytes.NewBufferString(`{"ErrorRate":1}`))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(1), provider.Faults().ErrorRate)
+	assert.Equal(t, 2*time.Second, provider.Faults().RetryAfter)
+
+	assert.Equal(t, http.StatusInternalServerError, post(t, server.URL, "secret").StatusCode)
+}
+
+func TestServer_SendsDeliveryReceipts(t *testing.T) {
+	receipts := make(chan deliveryReceipt, 1)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(constants.HeaderAccessToken) != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var receipt deliveryReceipt
+		_ = json.NewDecoder(r.Body).Decode(&receipt)
+		receipts <- receipt
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer callback.Close()
+
+	cfg := testConfig()
+	cfg.CallbackURL = callback.URL
+	cfg.CallbackToken = "token"
+	provider, server := startServer(t, cfg)
+
+	resp := post(t, server.URL, "secret")
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	select {
+	case receipt := <-receipts:
+		assert.Equal(t, provider.Recorder().Requests()[0].MessageID, receipt.MessageID)
+		assert.Equal(t, "+905551111111", receipt.To)
+		assert.Equal(t, "delivered", receipt.Status)
+	case <-time.After(2 * time.Second):
+		t.Fatal("delivery receipt was not sent")
+	}
+
+	provider.Close()
+	callbacks := provider.Recorder().Callbacks()
+	require.Len(t, callbacks, 1)
+	assert.Equal(t, http.StatusOK, callbacks[0].StatusCode)
+}
+
+func TestServer_ResetsRecordedRequests(t *testing.T) {
+	provider, server := startServer(t, testConfig())
+	post(t, server.URL, "secret")
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+constants.MockControlBasePath+constants.MockRequestsPath, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, provider.Recorder().Requests())
+}
+
+func TestLatency_Distributions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	uniform, err := newLatency(config.LatencyConfig{Distribution: constants.LatencyUniform, Min: 10 * time.Millisecond, Max: 20 * time.Millisecond})
+	require.NoError(t, err)
+	normal, err := newLatency(config.LatencyConfig{Distribution: constants.LatencyNormal, Mean: 50 * time.Millisecond, StdDev: 100 * time.Millisecond, Max: 80 * time.Millisecond})
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		d := uniform(rng)
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.Less(t, d, 20*time.Millisecond)
+
+		d = normal(rng)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, 80*time.Millisecond)
+	}
+
+	_, err = newLatency(config.LatencyConfig{Distribution: "pareto"})
+	assert.Error(t, err)
+}
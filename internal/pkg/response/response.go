@@ -28,6 +28,20 @@ func Unauthorized(c *gin.Context, code ErrorCode, message string) {
 	})
 }
 
+func NotFound(c *gin.Context, code ErrorCode, message string) {
+	ErrorResponse(c, http.StatusNotFound, &ErrorResult{
+		Code:    code,
+		Message: message,
+	})
+}
+
+func Conflict(c *gin.Context, code ErrorCode, message string) {
+	ErrorResponse(c, http.StatusConflict, &ErrorResult{
+		Code:    code,
+		Message: message,
+	})
+}
+
 func InternalServerError(c *gin.Context, code ErrorCode, message string, err error) {
 	result := &ErrorResult{
 		Code:    code,
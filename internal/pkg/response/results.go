@@ -8,6 +8,20 @@ const (
 	ErrorCodeSchedulerStartFailed     ErrorCode = "SCHEDULER_START_FAILED"
 	ErrorCodeSchedulerStopFailed      ErrorCode = "SCHEDULER_STOP_FAILED"
 	ErrorCodeFailedToRetrieveMessages ErrorCode = "FAILED_TO_RETRIEVE_MESSAGES"
+	ErrorCodeFailedToRetrieveMessage  ErrorCode = "FAILED_TO_RETRIEVE_MESSAGE"
+	ErrorCodeInvalidMessageID         ErrorCode = "INVALID_MESSAGE_ID"
+	ErrorCodeMessageNotFound          ErrorCode = "MESSAGE_NOT_FOUND"
+	ErrorCodeMessageNotQueued         ErrorCode = "MESSAGE_NOT_QUEUED"
+	ErrorCodeInvalidReceipt           ErrorCode = "INVALID_RECEIPT"
+	ErrorCodeFailedToRecordReceipt    ErrorCode = "FAILED_TO_RECORD_RECEIPT"
+	ErrorCodeInvalidAttachment        ErrorCode = "INVALID_ATTACHMENT"
+	ErrorCodeAttachmentNotFound       ErrorCode = "ATTACHMENT_NOT_FOUND"
+	ErrorCodeFailedToStoreAttachment  ErrorCode = "FAILED_TO_STORE_ATTACHMENT"
+	ErrorCodeFailedToRetrieveMedia    ErrorCode = "FAILED_TO_RETRIEVE_MEDIA"
+	ErrorCodeFailedToFlushCache       ErrorCode = "FAILED_TO_FLUSH_CACHE"
+	ErrorCodeArchivedMessageNotFound  ErrorCode = "ARCHIVED_MESSAGE_NOT_FOUND"
+	ErrorCodeMessageExists            ErrorCode = "MESSAGE_EXISTS"
+	ErrorCodeFailedToRestoreMessage   ErrorCode = "FAILED_TO_RESTORE_MESSAGE"
 	ErrorCodeUnauthorized             ErrorCode = "UNAUTHORIZED"
 	ErrorCodeUnauthorizedMissingToken ErrorCode = "UNAUTHORIZED_MISSING_TOKEN"
 	ErrorCodeUnauthorizedInvalidToken ErrorCode = "UNAUTHORIZED_INVALID_TOKEN"
@@ -21,6 +35,14 @@ const (
 	SuccessCodeSchedulerStopped         SuccessCode = "SCHEDULER_STOPPED"
 	SuccessCodeSchedulerStatusRetrieved SuccessCode = "SCHEDULER_STATUS_RETRIEVED"
 	SuccessCodeMessagesRetrieved        SuccessCode = "MESSAGES_RETRIEVED"
+	SuccessCodeMessageRetrieved         SuccessCode = "MESSAGE_RETRIEVED"
+	SuccessCodeReceiptRecorded          SuccessCode = "RECEIPT_RECORDED"
+	SuccessCodeAttachmentCreated        SuccessCode = "ATTACHMENT_CREATED"
+	SuccessCodeAttachmentsRetrieved     SuccessCode = "ATTACHMENTS_RETRIEVED"
+	SuccessCodeCacheStatsRetrieved      SuccessCode = "CACHE_STATS_RETRIEVED"
+	SuccessCodeCacheFlushed             SuccessCode = "CACHE_FLUSHED"
+	SuccessCodeArchiveStatsRetrieved    SuccessCode = "ARCHIVE_STATS_RETRIEVED"
+	SuccessCodeMessageRestored          SuccessCode = "MESSAGE_RESTORED"
 )
 
 type ErrorResult struct {
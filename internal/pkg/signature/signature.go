@@ -0,0 +1,167 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scheme is the prefix used for every signature in the signature header
+const Scheme = "sha256"
+
+// DefaultTolerance is the maximum allowed clock skew between signer and verifier
+const DefaultTolerance = 5 * time.Minute
+
+// Verification errors
+var (
+	ErrMissingSignature  = errors.New("signature header is missing")
+	ErrMissingTimestamp  = errors.New("timestamp header is missing")
+	ErrInvalidTimestamp  = errors.New("timestamp header is invalid")
+	ErrTimestampExpired  = errors.New("timestamp is outside the allowed tolerance")
+	ErrSignatureMismatch = errors.New("signature does not match")
+	ErrNoSecrets         = errors.New("no signing secrets configured")
+)
+
+// Compute returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func Compute(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signer signs outbound payloads with one or more active secrets.
+// During key rotation both the current and the previous secret are active,
+// so receivers that only know one of them can still verify the request.
+type Signer struct {
+	secrets []string
+}
+
+// NewSigner creates a new Signer, empty secrets are ignored
+func NewSigner(secrets ...string) *Signer {
+	return &Signer{secrets: nonEmpty(secrets)}
+}
+
+// Enabled reports whether at least one secret is configured
+func (s *Signer) Enabled() bool {
+	return s != nil && len(s.secrets) > 0
+}
+
+// Sign returns the timestamp and signature header values for body
+func (s *Signer) Sign(body []byte, now time.Time) (timestamp, signature string) {
+	timestamp = strconv.FormatInt(now.Unix(), 10)
+
+	parts := make([]string, 0, len(s.secrets))
+	for _, secret := range s.secrets {
+		parts = append(parts, Scheme+"="+Compute(secret, timestamp, body))
+	}
+
+	return timestamp, strings.Join(parts, ",")
+}
+
+// Verifier checks signatures produced by Signer
+type Verifier struct {
+	secrets   []string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewVerifier creates a new Verifier accepting any of the given secrets.
+// A zero tolerance falls back to DefaultTolerance.
+func NewVerifier(tolerance time.Duration, secrets ...string) *Verifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	return &Verifier{
+		secrets:   nonEmpty(secrets),
+		tolerance: tolerance,
+		now:       time.Now,
+	}
+}
+
+// Verify validates the signature and timestamp header values against body
+func (v *Verifier) Verify(signatureHeader, timestamp string, body []byte) error {
+	if len(v.secrets) == 0 {
+		return ErrNoSecrets
+	}
+	if signatureHeader == "" {
+		return ErrMissingSignature
+	}
+	if timestamp == "" {
+		return ErrMissingTimestamp
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	skew := v.now().Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > v.tolerance {
+		return ErrTimestampExpired
+	}
+
+	for _, candidate := range parseSignatures(signatureHeader) {
+		for _, secret := range v.secrets {
+			expected := Compute(secret, timestamp, body)
+			if hmac.Equal([]byte(candidate), []byte(expected)) {
+				return nil
+			}
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest validates an incoming HTTP request and restores its body
+// so that handlers can read it again
+func (v *Verifier) VerifyRequest(r *http.Request, signatureHeader, timestampHeader string) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return v.Verify(r.Header.Get(signatureHeader), r.Header.Get(timestampHeader), body)
+}
+
+// parseSignatures extracts the hex digests from a "sha256=<hex>,sha256=<hex>" header
+func parseSignatures(header string) []string {
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		scheme, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found || scheme != Scheme || value == "" {
+			continue
+		}
+		signatures = append(signatures, value)
+	}
+	return signatures
+}
+
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
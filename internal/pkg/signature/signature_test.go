@@ -0,0 +1,64 @@
+package signature
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"to":"+905551111111","content":"hello"}`)
+	signer := NewSigner("current")
+	timestamp, sig := signer.Sign(body, time.Now())
+
+	verifier := NewVerifier(time.Minute, "current")
+	assert.NoError(t, verifier.Verify(sig, timestamp, body))
+	assert.ErrorIs(t, verifier.Verify(sig, timestamp, []byte("tampered")), ErrSignatureMismatch)
+}
+
+func TestVerify_KeyRotation(t *testing.T) {
+	body := []byte("payload")
+	timestamp, sig := NewSigner("new", "old").Sign(body, time.Now())
+
+	// Receivers that have not rotated yet still accept the request
+	assert.NoError(t, NewVerifier(time.Minute, "old").Verify(sig, timestamp, body))
+	// Receivers that already rotated accept it too
+	assert.NoError(t, NewVerifier(time.Minute, "new").Verify(sig, timestamp, body))
+	// Unknown secrets are rejected
+	assert.ErrorIs(t, NewVerifier(time.Minute, "other").Verify(sig, timestamp, body), ErrSignatureMismatch)
+}
+
+func TestVerify_TimestampTolerance(t *testing.T) {
+	body := []byte("payload")
+	timestamp, sig := NewSigner("secret").Sign(body, time.Now().Add(-10*time.Minute))
+
+	verifier := NewVerifier(5*time.Minute, "secret")
+	assert.ErrorIs(t, verifier.Verify(sig, timestamp, body), ErrTimestampExpired)
+	assert.ErrorIs(t, verifier.Verify(sig, "not-a-number", body), ErrInvalidTimestamp)
+	assert.ErrorIs(t, verifier.Verify("", timestamp, body), ErrMissingSignature)
+	assert.ErrorIs(t, verifier.Verify(sig, "", body), ErrMissingTimestamp)
+}
+
+func TestVerifyRequest_RestoresBody(t *testing.T) {
+	body := []byte("payload")
+	timestamp, sig := NewSigner("secret").Sign(body, time.Now())
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("X-Signature", sig)
+	req.Header.Set("X-Timestamp", timestamp)
+
+	assert.NoError(t, NewVerifier(0, "secret").VerifyRequest(req, "X-Signature", "X-Timestamp"))
+
+	buf := new(bytes.Buffer)
+	_, _ = buf.ReadFrom(req.Body)
+	assert.Equal(t, body, buf.Bytes())
+}
+
+func TestSigner_Disabled(t *testing.T) {
+	assert.False(t, NewSigner("", "").Enabled())
+	assert.True(t, NewSigner("", "secret").Enabled())
+}
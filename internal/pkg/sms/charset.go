@@ -0,0 +1,30 @@
+package sms
+
+// GSM 03.38 character sets (3GPP TS 23.038)
+
+// gsm7Basic is the default GSM-7 alphabet, each character costs one septet
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension is the default extension table, reached through the escape
+// character so each character costs two septets
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// turkishSingleShift is the Turkish national language single shift table.
+// It replaces the extension table when the Turkish shift is indicated in the
+// UDH, characters cost two septets.
+const turkishSingleShift = "\f^{}\\[~]|ĞİŞç€ğış"
+
+var (
+	basicSet        = runeSet(gsm7Basic)
+	extensionSet    = runeSet(gsm7Extension)
+	turkishShiftSet = runeSet(turkishSingleShift)
+)
+
+func runeSet(chars string) map[rune]bool {
+	set := make(map[rune]bool, len(chars))
+	for _, r := range chars {
+		set[r] = true
+	}
+	return set
+}
@@ -0,0 +1,159 @@
+package sms
+
+import (
+	"fmt"
+	"unicode/utf16"
+)
+
+// Encoding is the data coding used to send a message
+type Encoding string
+
+const (
+	EncodingGSM7        Encoding = "GSM-7"
+	EncodingGSM7Turkish Encoding = "GSM-7-TR" // GSM-7 with the Turkish single shift table
+	EncodingUCS2        Encoding = "UCS-2"
+)
+
+// Segment capacities. Concatenated parts lose room to the 6 byte concatenation
+// UDH, the Turkish shift indicator costs 3 more bytes of UDH in every segment.
+const (
+	gsm7Single        = 160
+	gsm7Multi         = 153
+	gsm7TurkishSingle = 155
+	gsm7TurkishMulti  = 149
+	ucs2Single        = 70
+	ucs2Multi         = 67
+)
+
+// Analysis describes how content is encoded and billed
+type Analysis struct {
+	Encoding   Encoding `json:"encoding"`
+	Characters int      `json:"characters"` // Unicode code points
+	Units      int      `json:"units"`      // Septets for GSM-7, UTF-16 code units for UCS-2
+	Segments   int      `json:"segments"`
+	PerSegment int      `json:"per_segment"` // Units available in each segment of this message
+}
+
+// Analyze detects the cheapest encoding for content and counts its segments
+func Analyze(content string) Analysis {
+	encoding := DetectEncoding(content)
+	costs := unitCosts(content, encoding)
+	single, multi := capacity(encoding)
+
+	analysis := Analysis{
+		Encoding:   encoding,
+		Characters: len(costs),
+		PerSegment: single,
+	}
+	for _, cost := range costs {
+		analysis.Units += cost
+	}
+
+	switch {
+	case analysis.Units == 0:
+		analysis.Segments = 0
+	case analysis.Units <= single:
+		analysis.Segments = 1
+	default:
+		analysis.PerSegment = multi
+		analysis.Segments = len(pack(costs, multi))
+	}
+	return analysis
+}
+
+// DetectEncoding returns GSM-7 when every character is in the default alphabet
+// or extension table, GSM-7 with the Turkish shift when the Turkish single shift
+// table covers the rest, and UCS-2 otherwise
+func DetectEncoding(content string) Encoding {
+	encoding := EncodingGSM7
+	for _, r := range content {
+		switch {
+		case basicSet[r] || extensionSet[r]:
+		case turkishShiftSet[r]:
+			encoding = EncodingGSM7Turkish
+		default:
+			return EncodingUCS2
+		}
+	}
+	return encoding
+}
+
+// unitCosts returns the number of units each rune of content takes
+func unitCosts(content string, encoding Encoding) []int {
+	costs := make([]int, 0, len(content))
+	for _, r := range content {
+		switch encoding {
+		case EncodingUCS2:
+			costs = append(costs, len(utf16.Encode([]rune{r})))
+		case EncodingGSM7Turkish:
+			if basicSet[r] {
+				costs = append(costs, 1)
+			} else {
+				costs = append(costs, 2)
+			}
+		default:
+			if extensionSet[r] {
+				costs = append(costs, 2)
+			} else {
+				costs = append(costs, 1)
+			}
+		}
+	}
+	return costs
+}
+
+// pack greedily fills segments of the given capacity and returns the
+// exclusive rune index at which each segment ends
+func pack(costs []int, perSegment int) []int {
+	var bounds []int
+	used := 0
+	for i, cost := range costs {
+		if used+cost > perSegment {
+			bounds = append(bounds, i)
+			used = 0
+		}
+		used += cost
+	}
+	if used > 0 {
+		bounds = append(bounds, len(costs))
+	}
+	return bounds
+}
+
+func capacity(encoding Encoding) (single, multi int) {
+	switch encoding {
+	case EncodingUCS2:
+		return ucs2Single, ucs2Multi
+	case EncodingGSM7Turkish:
+		return gsm7TurkishSingle, gsm7TurkishMulti
+	default:
+		return gsm7Single, gsm7Multi
+	}
+}
+
+// Split cuts content into the parts it is sent as when concatenated, never
+// splitting an escape sequence or a surrogate pair across parts
+func Split(content string) []string {
+	analysis := Analyze(content)
+	if analysis.Segments <= 1 {
+		if content == "" {
+			return nil
+		}
+		return []string{content}
+	}
+
+	runes := []rune(content)
+	parts := make([]string, 0, analysis.Segments)
+	start := 0
+	for _, end := range pack(unitCosts(content, analysis.Encoding), analysis.PerSegment) {
+		parts = append(parts, string(runes[start:end]))
+		start = end
+	}
+	return parts
+}
+
+// ConcatUDH returns the hex encoded User Data Header identifying part sequence
+// of total in the concatenated message with the given 8-bit reference
+func ConcatUDH(reference, total, sequence int) string {
+	return fmt.Sprintf("050003%02X%02X%02X", byte(reference), byte(total), byte(sequence))
+}
@@ -0,0 +1,96 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Encoding
+	}{
+		{"plain ascii", "Hello world", EncodingGSM7},
+		{"default alphabet accents", "Çok güzel, Öğle", EncodingGSM7Turkish},
+		{"extension table", "Price: 5€ [promo]", EncodingGSM7},
+		{"turkish dotless i", "Merhaba, bu bir test mesajıdır.", EncodingGSM7Turkish},
+		{"turkish capital dotted i", "İkinci test mesajı", EncodingGSM7Turkish},
+		{"umlauts only", "Ünlü ödül", EncodingGSM7},
+		{"emoji", "Hello 👋", EncodingUCS2},
+		{"cyrillic", "Привет", EncodingUCS2},
+		{"empty", "", EncodingGSM7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectEncoding(tt.content))
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		encoding Encoding
+		units    int
+		segments int
+	}{
+		{"empty", "", EncodingGSM7, 0, 0},
+		{"single gsm7", strings.Repeat("a", 160), EncodingGSM7, 160, 1},
+		{"two gsm7", strings.Repeat("a", 161), EncodingGSM7, 161, 2},
+		{"extension chars count double", strings.Repeat("€", 80), EncodingGSM7, 160, 1},
+		{"extension overflow", strings.Repeat("€", 81), EncodingGSM7, 162, 2},
+		{"turkish single", strings.Repeat("a", 154) + "ı", EncodingGSM7Turkish, 156, 2},
+		{"turkish fits", strings.Repeat("a", 153) + "ı", EncodingGSM7Turkish, 155, 1},
+		{"single ucs2", strings.Repeat("ж", 70), EncodingUCS2, 70, 1},
+		{"two ucs2", strings.Repeat("ж", 71), EncodingUCS2, 71, 2},
+		{"surrogate pairs", strings.Repeat("👋", 35), EncodingUCS2, 70, 1},
+		{"three gsm7", strings.Repeat("a", 307), EncodingGSM7, 307, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := Analyze(tt.content)
+			assert.Equal(t, tt.encoding, analysis.Encoding)
+			assert.Equal(t, tt.units, analysis.Units)
+			assert.Equal(t, tt.segments, analysis.Segments)
+		})
+	}
+}
+
+func TestAnalyze_DoesNotSplitEscapeSequences(t *testing.T) {
+	// 152 septets then a two septet character: it cannot share the first part
+	content := strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10)
+	analysis := Analyze(content)
+	assert.Equal(t, 2, analysis.Segments)
+	assert.Equal(t, gsm7Multi, analysis.PerSegment)
+}
+
+func TestSplit(t *testing.T) {
+	assert.Nil(t, Split(""))
+	assert.Equal(t, []string{"short"}, Split("short"))
+
+	content := strings.Repeat("a", 152) + "€" + strings.Repeat("b", 10)
+	parts := Split(content)
+	assert.Equal(t, []string{strings.Repeat("a", 152), "€" + strings.Repeat("b", 10)}, parts)
+	assert.Equal(t, content, strings.Join(parts, ""))
+
+	parts = Split(strings.Repeat("ı", 200))
+	assert.Len(t, parts, 3)
+	for _, part := range parts[:2] {
+		assert.Equal(t, gsm7TurkishMulti/2, len([]rune(part)))
+	}
+
+	parts = Split(strings.Repeat("👋", 40))
+	assert.Len(t, parts, 2)
+	assert.Equal(t, 33, len([]rune(parts[0])))
+}
+
+func TestConcatUDH(t *testing.T) {
+	assert.Equal(t, "0500032A0301", ConcatUDH(42, 3, 1))
+	assert.Equal(t, "050003010202", ConcatUDH(257, 2, 2))
+}
@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations of every supported database,
+// one directory per dialect
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql sqlite/*.sql mysql/*.sql
+var FS embed.FS
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS messages (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `to` VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    message_id VARCHAR(255),
+    retry_count INT DEFAULT 0 NOT NULL,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE INDEX idx_messages_status ON messages(status);
+CREATE INDEX idx_messages_status_created ON messages(status, created_at);
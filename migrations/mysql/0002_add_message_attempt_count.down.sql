@@ -0,0 +1 @@
+ALTER TABLE messages DROP COLUMN last_attempt_at, DROP COLUMN attempt_count;
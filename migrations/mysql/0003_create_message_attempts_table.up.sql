@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS message_attempts (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    message_id BIGINT UNSIGNED NOT NULL,
+    provider VARCHAR(50),
+    attempt_number INT NOT NULL,
+    requested_at DATETIME(3) NOT NULL,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    status_code INT,
+    response_body TEXT,
+    error TEXT,
+    throttled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    CONSTRAINT fk_message_attempts_message FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE INDEX idx_message_attempts_message_id ON message_attempts(message_id);
+CREATE INDEX idx_message_attempts_created_at ON message_attempts(created_at);
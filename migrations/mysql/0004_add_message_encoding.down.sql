@@ -0,0 +1 @@
+ALTER TABLE messages DROP COLUMN segment_count, DROP COLUMN encoding;
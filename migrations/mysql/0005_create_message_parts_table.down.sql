@@ -0,0 +1,3 @@
+ALTER TABLE message_attempts DROP COLUMN part_number;
+
+DROP TABLE IF EXISTS message_parts;
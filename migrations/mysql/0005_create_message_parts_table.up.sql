@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS message_parts (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    message_id BIGINT UNSIGNED NOT NULL,
+    part_number INT NOT NULL,
+    total_parts INT NOT NULL,
+    reference INT NOT NULL,
+    content TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    provider_id VARCHAR(255),
+    attempt_count INT DEFAULT 0 NOT NULL,
+    error TEXT,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    CONSTRAINT fk_message_parts_message FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE UNIQUE INDEX idx_message_parts_message_part ON message_parts(message_id, part_number);
+
+ALTER TABLE message_attempts ADD COLUMN part_number INT DEFAULT 0 NOT NULL;
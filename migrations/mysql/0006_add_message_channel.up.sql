@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN channel VARCHAR(20) DEFAULT 'sms' NOT NULL;
+ALTER TABLE messages ADD COLUMN subject VARCHAR(255);
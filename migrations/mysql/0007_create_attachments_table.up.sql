@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    message_id BIGINT UNSIGNED NOT NULL,
+    url TEXT NOT NULL,
+    storage_key VARCHAR(255),
+    file_name VARCHAR(255),
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    CONSTRAINT fk_attachments_message FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE INDEX idx_attachments_message_id ON attachments(message_id);
+CREATE INDEX idx_attachments_storage_key ON attachments(storage_key);
+CREATE INDEX idx_attachments_created_at ON attachments(created_at);
@@ -0,0 +1,2 @@
+DROP INDEX idx_message_parts_provider_id ON message_parts;
+DROP INDEX idx_messages_message_id ON messages;
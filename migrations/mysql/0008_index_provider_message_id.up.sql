@@ -0,0 +1,2 @@
+CREATE INDEX idx_messages_message_id ON messages(message_id);
+CREATE INDEX idx_message_parts_provider_id ON message_parts(provider_id);
@@ -0,0 +1,2 @@
+DROP INDEX idx_messages_created_at ON messages;
+DROP TABLE IF EXISTS message_archives;
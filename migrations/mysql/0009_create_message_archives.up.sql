@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS message_archives (
+    id BIGINT UNSIGNED PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    archived_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    payload LONGTEXT NOT NULL
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE INDEX idx_message_archives_archived_at ON message_archives(archived_at);
+CREATE INDEX idx_messages_created_at ON messages(created_at);
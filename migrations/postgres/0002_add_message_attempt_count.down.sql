@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS last_attempt_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS attempt_count;
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS attempt_count INT DEFAULT 0 NOT NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS last_attempt_at TIMESTAMPTZ;
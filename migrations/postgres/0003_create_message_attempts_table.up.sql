@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS message_attempts (
+    id SERIAL PRIMARY KEY,
+    message_id INT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    provider VARCHAR(50),
+    attempt_number INT NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    status_code INT,
+    response_body TEXT,
+    error TEXT,
+    throttled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_attempts_message_id ON message_attempts(message_id);
+CREATE INDEX IF NOT EXISTS idx_message_attempts_created_at ON message_attempts(created_at);
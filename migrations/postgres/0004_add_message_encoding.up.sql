@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS encoding VARCHAR(10);
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS segment_count INT DEFAULT 0 NOT NULL;
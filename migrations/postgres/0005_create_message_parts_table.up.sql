@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS message_parts (
+    id SERIAL PRIMARY KEY,
+    message_id INT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    part_number INT NOT NULL,
+    total_parts INT NOT NULL,
+    reference INT NOT NULL,
+    content TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    provider_id VARCHAR(255),
+    attempt_count INT DEFAULT 0 NOT NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_message_parts_message_part ON message_parts(message_id, part_number);
+
+ALTER TABLE message_attempts ADD COLUMN IF NOT EXISTS part_number INT DEFAULT 0 NOT NULL;
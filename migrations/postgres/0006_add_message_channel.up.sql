@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS channel VARCHAR(20) DEFAULT 'sms' NOT NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS subject VARCHAR(255);
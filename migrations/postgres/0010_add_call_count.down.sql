@@ -0,0 +1,2 @@
+ALTER TABLE message_parts DROP COLUMN IF EXISTS call_count;
+ALTER TABLE messages DROP COLUMN IF EXISTS call_count;
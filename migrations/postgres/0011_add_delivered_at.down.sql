@@ -0,0 +1,2 @@
+ALTER TABLE message_parts DROP COLUMN IF EXISTS delivered_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS delivered_at;
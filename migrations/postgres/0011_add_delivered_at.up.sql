@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
+ALTER TABLE message_parts ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
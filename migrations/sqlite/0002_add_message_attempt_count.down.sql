@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN last_attempt_at;
+ALTER TABLE messages DROP COLUMN attempt_count;
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN attempt_count INT DEFAULT 0 NOT NULL;
+ALTER TABLE messages ADD COLUMN last_attempt_at DATETIME;
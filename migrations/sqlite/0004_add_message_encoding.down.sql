@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN segment_count;
+ALTER TABLE messages DROP COLUMN encoding;
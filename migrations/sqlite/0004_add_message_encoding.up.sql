@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN encoding VARCHAR(10);
+ALTER TABLE messages ADD COLUMN segment_count INT DEFAULT 0 NOT NULL;
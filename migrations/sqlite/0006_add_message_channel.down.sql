@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN subject;
+ALTER TABLE messages DROP COLUMN channel;
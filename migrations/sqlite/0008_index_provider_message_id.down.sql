@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_message_parts_provider_id;
+DROP INDEX IF EXISTS idx_messages_message_id;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_messages_created_at;
+DROP TABLE IF EXISTS message_archives;
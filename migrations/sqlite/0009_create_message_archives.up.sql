@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS message_archives (
+    id INTEGER PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    created_at DATETIME NOT NULL,
+    archived_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    payload TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_archives_archived_at ON message_archives(archived_at);
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
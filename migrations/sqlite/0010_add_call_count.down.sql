@@ -0,0 +1,2 @@
+ALTER TABLE message_parts DROP COLUMN call_count;
+ALTER TABLE messages DROP COLUMN call_count;
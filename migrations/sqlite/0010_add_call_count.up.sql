@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN call_count INT DEFAULT 0 NOT NULL;
+ALTER TABLE message_parts ADD COLUMN call_count INT DEFAULT 0 NOT NULL;
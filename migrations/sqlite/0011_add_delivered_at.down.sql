@@ -0,0 +1,2 @@
+ALTER TABLE message_parts DROP COLUMN delivered_at;
+ALTER TABLE messages DROP COLUMN delivered_at;
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN delivered_at DATETIME;
+ALTER TABLE message_parts ADD COLUMN delivered_at DATETIME;
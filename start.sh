@@ -69,6 +69,7 @@ docker run -d \
   -e ACCESS_TOKEN=${ACCESS_TOKEN:-your-access-token} \
   -e SCHEDULER_INTERVAL=${SCHEDULER_INTERVAL:-2m} \
   -e SCHEDULER_AUTO_START=${SCHEDULER_AUTO_START:-true} \
+  -e SEED_ON_START=${SEED_ON_START:-true} \
   insider-case || exit 1
 
 echo "Waiting for application to start..."